@@ -35,19 +35,131 @@ comments["annotation_match"](str) if regex.match(
 
 # METADATA
 # description: |
-#   map of all ignore directive comments, like ("# regal ignore:line-length")
-#   found in input AST, indexed by the row they're at
-ignore_directives[row] := rules if {
+#   map of all ignore directive comments found in input AST, indexed by the row they apply to,
+#   where rules are referenced by title, by category and title, or by category for all rules
+#   in it, like "style/*":
+#   - ignore directives apply to the row following the directive (and the row of the directive
+#     itself, when placed at the end of a line)
+#   - disable directives apply to all rows from the directive, up until an enable directive for
+#     the same rule, or the end of the file
+#   - ignore-file directives apply to the whole file, and are indexed by row 0
+ignore_directives[row] := sort(rules) if {
+	some [row, _] in _ignored_rows
+	rules := {rule | some [r, rule] in _ignored_rows; r == row}
+}
+
+# set of [row, rule] pairs, for each row a rule is ignored at
+_ignored_rows contains [row, rule] if {
+	some directive in _directives
+	directive.kind == "ignore"
+
+	row := directive.row + 1
+
+	some rule in directive.rules
+}
+
+_ignored_rows contains [0, rule] if {
+	some directive in _directives
+	directive.kind == "ignore-file"
+
+	some rule in directive.rules
+}
+
+_ignored_rows contains [row, rule] if {
+	some directive in _directives
+	directive.kind == "disable"
+
+	some rule in directive.rules
+
+	end := _range_end(
+		directive.row,
+		{enabled | some [r, enabled] in _enabled_rows; r == rule},
+		count(object.get(input, ["regal", "file", "lines"], [])) + 1,
+	)
+	end - 1 > directive.row
+
+	some row in numbers.range(directive.row + 1, end - 1)
+}
+
+# set of [rule, row] pairs, for each enable directive of a rule
+_enabled_rows contains [rule, directive.row] if {
+	some directive in _directives
+	directive.kind == "enable"
+
+	some rule in directive.rules
+}
+
+# the first of the rows where a rule is enabled again after row, or end_of_file, if none
+_range_end(row, enabled_rows, end_of_file) := min(ends) if {
+	ends := {enabled | some enabled in enabled_rows; enabled > row}
+	count(ends) > 0
+} else := end_of_file
+
+_directive_kinds := ["ignore", "ignore-file", "disable", "enable"]
+
+# METADATA
+# description: |
+#   set of all ignore directive comments found in input AST, with the kind of directive (ignore,
+#   ignore-file, disable or enable), the rules referenced and the location of the comment, along
+#   with the optional reason and until date (YYYY-MM-DD) attributes, where directives with an
+#   until date in the past are marked expired, and no longer applied
+ignore_directive_comments contains directive if {
 	some comment in comments_decoded
 	text := trim_space(comment.Text)
 
-	i := indexof(text, "regal ignore:")
+	some kind in _directive_kinds
+
+	prefix := concat("", ["regal ", kind, ":"])
+	i := indexof(text, prefix)
 	i != -1
 
-	list := regex.replace(substring(text, i + 13, -1), `\s`, "")
+	rest := substring(text, i + count(prefix), -1)
+	attributes := {key: trim(value, `"`) |
+		some [_, key, value] in regex.find_all_string_submatch_n(_attribute_pattern, rest, -1)
+	}
+	list := regex.replace(regex.replace(rest, _attribute_pattern, ""), `\s`, "")
+
+	directive := object.union_n([
+		{
+			"kind": kind,
+			"rules": [rule | some rule in split(list, ","); rule != ""],
+			"location": _directive_location(comment),
+		},
+		attributes,
+		_expired(object.get(attributes, "until", "")),
+	])
+}
+
+_attribute_pattern := `\b(reason|until)=("[^"]*"|\S+)`
+
+_directive_location(comment) := {
+	"row": comment.Location.row,
+	"col": comment.Location.col,
+	"text": _line(
+		object.get(input, ["regal", "file", "lines"], []),
+		comment.Location.row,
+		concat("", ["#", comment.Text]),
+	),
+}
+
+_line(lines, row, _) := lines[row - 1]
+
+_line(lines, row, default_text) := default_text if not lines[row - 1]
+
+# a directive applies until the end of the day named, i.e. it expires at 00:00 UTC the day after
+_expired(until) := {"expired": true} if time.add_date(time.parse_ns("2006-01-02", until), 0, 0, 1) < time.now_ns()
+
+else := {}
+
+_directives contains directive if {
+	some comment in ignore_directive_comments
+	not comment.expired
 
-	row := comment.Location.row + 1
-	rules := split(list, ",")
+	directive := {
+		"kind": comment.kind,
+		"row": comment.location.row,
+		"rules": comment.rules,
+	}
 }
 
 # METADATA
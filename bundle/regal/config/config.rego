@@ -9,7 +9,14 @@ docs["resolve_url"](url, category) := replace(
 	"$category", category,
 )
 
-merged_config := data.internal.combined_config
+# METADATA
+# description: |
+#   the configuration applied to the file linted, which is the combined configuration,
+#   unless the configuration of the file differs, like when config files are found in
+#   .regal directories nearer to the file, or overrides in config files apply to it
+merged_config := file_config if {
+	file_config := data.internal.configs[data.internal.file_config_ids[input.regal.file.name]]
+} else := data.internal.combined_config
 
 capabilities := merged_config.capabilities
 
@@ -168,7 +168,14 @@ test_all_rules_are_in_provided_configuration if {
 }
 
 test_all_configured_rules_exist if {
-	go_rules := {"opa-fmt"}
+	go_rules := {
+		"compile-error",
+		"non-indexable-rule",
+		"opa-fmt",
+		"schema-mismatch",
+		"unsupported-capability",
+		"unused-ignore-directive",
+	}
 
 	missing_rules := {title |
 		some category, title
@@ -178,3 +185,25 @@ test_all_configured_rules_exist if {
 
 	count(missing_rules - go_rules) == 0
 }
+
+test_merged_config_of_file if {
+	file_config := {"rules": {"test": {"test-case": {"level": "warning"}}}}
+
+	c := config.for_rule("test", "test-case") with data.eval.params as params
+		with data.internal.combined_config as rules_config
+		with data.internal.configs as {"0": file_config}
+		with data.internal.file_config_ids as {"p.rego": "0"}
+		with input.regal.file.name as "p.rego"
+
+	c == {"level": "warning"}
+}
+
+test_merged_config_of_file_without_own_config if {
+	c := config.for_rule("test", "test-case") with data.eval.params as params
+		with data.internal.combined_config as rules_config
+		with data.internal.configs as {"0": {}}
+		with data.internal.file_config_ids as {"other.rego": "0"}
+		with input.regal.file.name as "p.rego"
+
+	c == {"level": "ignore", "important_setting": 42}
+}
@@ -85,7 +85,6 @@ allow if {
 	}
 }
 
-# regal ignore:rule-length
 test_snippet_completion_on_typing_no_repeat if {
 	policy := `package policy
 
@@ -12,8 +12,14 @@ lint.aggregates := aggregate
 
 lint.ignore_directives[input.regal.file.name] := ast.ignore_directives
 
+lint.directives[input.regal.file.name] := ast.ignore_directive_comments
+
+lint.suppressed := suppressed
+
 lint_aggregate.violations := aggregate_report
 
+lint_aggregate.suppressed := aggregate_suppressed
+
 lint.violations := report
 
 rules_to_run[category][title] if {
@@ -64,28 +70,38 @@ report contains violation if {
 	}
 }
 
-# Check bundled rules
 report contains violation if {
+	some violation in _violations
+
+	not ignored(violation, ast.ignore_directives)
+}
+
+# METADATA
+# description: violations of rules suppressed by ignore directives
+suppressed contains violation if {
+	some violation in _violations
+
+	ignored(violation, ast.ignore_directives)
+}
+
+# Check bundled rules
+_violations contains violation if {
 	some category, title
 	rules_to_run[category][title]
 
 	count(object.get(grouped_notices, [category, title], [])) == 0
 
 	some violation in data.regal.rules[category][title].report
-
-	not ignored(violation, ast.ignore_directives)
 }
 
 # Check custom rules
-report contains violation if {
+_violations contains violation if {
 	some category, title
 
 	violation := data.custom.regal.rules[category][title].report[_]
 
 	config.for_rule(category, title).level != "ignore"
 	not config.excluded_file(category, title, input.regal.file.name)
-
-	not ignored(violation, ast.ignore_directives)
 }
 
 # Collect aggregates in bundled rules
@@ -110,11 +126,25 @@ aggregate[category_title] contains entry if {
 	category_title := concat("/", [category, title])
 }
 
+aggregate_report contains violation if {
+	some violation in _aggregate_violations
+
+	not _ignored_in_file(violation)
+}
+
+# METADATA
+# description: violations of aggregate rules suppressed by ignore directives
+aggregate_suppressed contains violation if {
+	some violation in _aggregate_violations
+
+	_ignored_in_file(violation)
+}
+
 # METADATA
 # description: Check bundled rules using aggregated data
 # schemas:
 #   - input: schema.regal.aggregate
-aggregate_report contains violation if {
+_aggregate_violations contains file_violation if {
 	some category, title
 	rules_to_run[category][title]
 
@@ -127,16 +157,17 @@ aggregate_report contains violation if {
 	# regal ignore:with-outside-test-context
 	some violation in data.regal.rules[category][title].aggregate_report with input as input_for_rule
 
-	ignore_directives := object.get(input.ignore_directives, violation.location.file, {})
+	# unlike for custom rules, violations of bundled rules are only reported when located in a file
+	violation.location.file
 
-	not ignored(violation, util.keys_to_numbers(ignore_directives))
+	file_violation := _with_file_level(violation, category, title, object.get(data.internal, "file_config_ids", {}))
 }
 
 # METADATA
 # description: Check custom rules using aggregated data
 # schemas:
 #   - input: schema.regal.aggregate
-aggregate_report contains violation if {
+_aggregate_violations contains file_violation if {
 	some key in object.keys(input.aggregates_internal)
 	[category, title] := split(key, "/")
 
@@ -151,20 +182,46 @@ aggregate_report contains violation if {
 	# regal ignore:with-outside-test-context
 	some violation in data.custom.regal.rules[category][title].aggregate_report with input as input_for_rule
 
-	# for custom rules, we can't assume that the author included
-	# a location in the violation, although they _really_ should
-	file := object.get(violation, ["location", "file"], "")
-	ignore_directives := object.get(input.ignore_directives, file, {})
+	file_violation := _with_file_level(violation, category, title, object.get(data.internal, "file_config_ids", {}))
+}
 
-	not ignored(violation, util.keys_to_numbers(ignore_directives))
+# METADATA
+# description: |
+#   Check ignore directives of the file of a violation of an aggregate rule, where
+#   for custom rules, we can't assume that the author included a location in the
+#   violation, although they _really_ should
+# schemas:
+#   - input: schema.regal.aggregate
+_ignored_in_file(violation) if {
+	ignore_directives := object.get(input.ignore_directives, object.get(violation, ["location", "file"], ""), {})
+
+	ignored(violation, util.keys_to_numbers(ignore_directives))
 }
 
-ignored(violation, directives) if {
-	ignored_rules := directives[violation.location.row]
-	violation.title in ignored_rules
+# aggregate rules are evaluated once for all files, so the level of violations in
+# files with configuration of their own is set according to that configuration,
+# and violations of rules ignored in those files are dropped
+_with_file_level(violation, category, title, file_config_ids) := object.union(violation, {"level": level}) if {
+	file := violation.location.file
+	file_config_ids[file]
+
+	# regal ignore:with-outside-test-context,external-reference
+	level := config.for_rule(category, title).level with input.regal.file.name as file
+	level != "ignore"
+} else := violation if {
+	not file_config_ids[object.get(violation, ["location", "file"], "")]
 }
 
 ignored(violation, directives) if {
-	ignored_rules := directives[violation.location.row + 1]
-	violation.title in ignored_rules
+	some row in [0, violation.location.row, violation.location.row + 1]
+	some directive in directives[row]
+
+	_matches(directive, violation)
 }
+
+# directives reference rules by title, by category and title, or by category for all rules in it
+_matches(directive, violation) if directive == violation.title
+
+_matches(directive, violation) if directive == concat("/", [object.get(violation, "category", ""), violation.title])
+
+_matches(directive, violation) if directive == concat("", [object.get(violation, "category", ""), "/*"])
@@ -2,6 +2,7 @@ package regal.main_test
 
 import rego.v1
 
+import data.regal.ast
 import data.regal.config
 import data.regal.main
 
@@ -134,6 +135,181 @@ test_ignore_directive_multiple_mixed_success if {
 	count(report) == 1
 }
 
+test_ignore_directive_category_wildcard_success if {
+	policy := `package p
+
+	# regal ignore:style/*
+	default camelCase = "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {
+			"prefer-snake-case": {"level": "error"},
+			"use-assignment-operator": {"level": "error"},
+		}}}
+
+	count(report) == 0
+}
+
+test_ignore_directive_category_and_title_success if {
+	policy := `package p
+
+	# regal ignore:style/prefer-snake-case
+	camelCase := "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(report) == 0
+}
+
+test_ignore_directive_other_category_failure if {
+	policy := `package p
+
+	# regal ignore:idiomatic/*
+	camelCase := "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(report) == 1
+}
+
+test_ignore_file_directive_success if {
+	policy := `package p
+
+	# regal ignore-file:prefer-snake-case
+
+	camelCase := "yes"
+
+	otherCamelCase := "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(report) == 0
+}
+
+test_disable_enable_directives_range if {
+	policy := `package p
+
+	before_camelCase := "yes"
+
+	# regal disable:prefer-snake-case
+	first_camelCase := "yes"
+
+	second_camelCase := "yes"
+	# regal enable:prefer-snake-case
+
+	after_camelCase := "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	{violation.location.row | some violation in report} == {3, 11}
+}
+
+test_disable_directive_without_enable_applies_to_end_of_file if {
+	policy := `package p
+
+	before_camelCase := "yes"
+
+	# regal disable:style/*
+	first_camelCase := "yes"
+
+	second_camelCase := "yes"
+	`
+	report := main.report with input as regal.parse_module("p.rego", policy)
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	{violation.location.row | some violation in report} == {3}
+}
+
+test_ignore_directives_indexed_by_row if {
+	module := regal.parse_module("p.rego", `package p
+
+	# regal ignore-file:opa-fmt
+	# regal disable:line-length
+	x := 1
+	# regal enable:line-length
+
+	# regal ignore:style/*
+	y := 2
+	`)
+
+	ast.ignore_directives == {
+		0: ["opa-fmt"],
+		5: ["line-length"],
+		9: ["style/*"],
+	} with input as module
+}
+
+test_ignore_directive_comments_with_reason_and_until if {
+	module := regal.parse_module("p.rego", `package p
+
+# regal ignore:prefer-snake-case,style/line-length reason="legacy name" until=2999-12-31
+camelCase := "yes"
+`)
+
+	ast.ignore_directive_comments == {{
+		"kind": "ignore",
+		"rules": ["prefer-snake-case", "style/line-length"],
+		"location": {
+			"row": 3,
+			"col": 1,
+			"text": `# regal ignore:prefer-snake-case,style/line-length reason="legacy name" until=2999-12-31`,
+		},
+		"reason": "legacy name",
+		"until": "2999-12-31",
+	}} with input as module
+}
+
+test_expired_ignore_directive_not_applied if {
+	module := regal.parse_module("p.rego", `package p
+
+	# regal ignore:prefer-snake-case until=2000-01-01
+	camelCase := "yes"
+	`)
+
+	report := main.report with input as module
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(report) == 1
+
+	some directive in ast.ignore_directive_comments with input as module
+	directive.expired == true
+}
+
+test_ignore_directive_applied_on_until_date if {
+	today := time.format([time.now_ns(), "UTC", "2006-01-02"])
+	module := regal.parse_module("p.rego", concat("", [
+		"package p\n\n",
+		"# regal ignore:prefer-snake-case until=", today, "\n",
+		"camelCase := \"yes\"\n",
+	]))
+
+	report := main.report with input as module
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(report) == 0
+
+	some directive in ast.ignore_directive_comments with input as module
+	not directive.expired
+}
+
+test_suppressed_violations if {
+	module := regal.parse_module("p.rego", `package p
+
+	# regal ignore:prefer-snake-case
+	camelCase := "yes"
+	`)
+
+	lint := main.lint with input as module
+		with config.merged_config as {"rules": {"style": {"prefer-snake-case": {"level": "error"}}}}
+
+	count(lint.violations) == 0
+	{violation.title | some violation in lint.suppressed} == {"prefer-snake-case"}
+}
+
 test_ignore_directive_collected_in_aggregate_rule if {
 	module := regal.parse_module("p.rego", `package p
 
@@ -179,6 +355,23 @@ test_ignore_directive_enforced_in_aggregate_rule if {
 	count(report_with_ignore_directives) == 0
 }
 
+test_aggregate_violations_suppressed if {
+	suppressed := main.aggregate_suppressed with input as {
+		"aggregates_internal": {"imports/unresolved-import": []},
+		"regal": {"file": {"name": "p.rego"}},
+		"ignore_directives": {"p.rego": {"6": ["unresolved-import"]}},
+	}
+		with config.merged_config as {"rules": {"imports": {"unresolved-import": {"level": "error"}}}}
+		with data.regal.rules.imports["unresolved-import"].aggregate_report as {{
+			"category": "imports",
+			"level": "error",
+			"location": {"col": 1, "file": "p.rego", "row": 6, "text": "import data.provider.parameters"},
+			"title": "unresolved-import",
+		}}
+
+	{violation.title | some violation in suppressed} == {"unresolved-import"}
+}
+
 test_exclude_files_rule_config if {
 	policy := `package p
 
@@ -215,3 +408,20 @@ test_force_exclude_file_config if {
 
 	count(report) == 0
 }
+
+test_ignore_file_directive_enforced_in_aggregate_rule if {
+	report := main.aggregate_report with input as {
+		"aggregates_internal": {"imports/unresolved-import": []},
+		"regal": {"file": {"name": "p.rego"}},
+		"ignore_directives": {"p.rego": {"0": ["imports/*"]}},
+	}
+		with config.merged_config as {"rules": {"imports": {"unresolved-import": {"level": "error"}}}}
+		with data.regal.rules.imports["unresolved-import"].aggregate_report as {{
+			"category": "imports",
+			"level": "error",
+			"location": {"col": 1, "file": "p.rego", "row": 6, "text": "import data.provider.parameters"},
+			"title": "unresolved-import",
+		}}
+
+	count(report) == 0
+}
@@ -43,7 +43,6 @@ aggregate(chain, aggregate_data) := entry if {
 			# regal ignore:external-reference
 			"file": input.regal.file.name,
 			"package_path": [part.value |
-				# regal ignore:external-reference
 				some i, part in input["package"].path
 				i > 0
 			],
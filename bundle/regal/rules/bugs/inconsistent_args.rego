@@ -10,15 +10,13 @@ import data.regal.result
 report contains violation if {
 	count(ast.functions) > 0
 
-	# Comprehension indexing — as made obvious here it would be great
-	# to have block level scoped ignore directives...
 	function_args_by_name := {name: args_list |
 		some i
-		name := ast.ref_to_string(ast.functions[i].head.ref) # regal ignore:prefer-some-in-iteration
+		name := ast.ref_to_string(ast.functions[i].head.ref)
 		args_list := [args |
 			some j
-			ast.ref_to_string(ast.functions[j].head.ref) == name # regal ignore:prefer-some-in-iteration
-			args := ast.functions[j].head.args # regal ignore:prefer-some-in-iteration
+			ast.ref_to_string(ast.functions[j].head.ref) == name
+			args := ast.functions[j].head.args
 		]
 		count(args_list) > 1
 	}
@@ -0,0 +1,178 @@
+# METADATA
+# description: Rule or function never referenced
+package regal.rules.bugs["unused-rule"]
+
+import rego.v1
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+import data.regal.util
+
+cfg := config.for_rule("bugs", "unused-rule")
+
+package_path := [part.value | some i, part in input["package"].path; i > 0]
+
+aggregate contains entry if {
+	defined_rules := [rule_def |
+		some rule in input.rules
+
+		not _test_rule(rule)
+		not _excepted_package(package_path)
+
+		path := array.concat(package_path, _static_path(rule.head.ref))
+
+		not _excepted_rule(path)
+
+		rule_def := object.union(result.location(rule.head), {"path": path})
+	]
+
+	entry := result.aggregate(rego.metadata.chain(), {
+		"rules": defined_rules,
+		"refs": _referenced_paths,
+		"entrypoints": _entrypoints,
+	})
+}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	all_refs := {path |
+		some entry in input.aggregate
+		some path in entry.aggregate_data.refs
+	}
+
+	# any prefix of a referenced path, as referencing data.a.b.c means that rule a.b
+	# (producing an object) is used, just as referencing data.a.b means a.b.c is
+	all_ref_prefixes := {prefix |
+		some path in all_refs
+		some prefix in util.all_paths(path)
+	}
+
+	all_entrypoints := {path |
+		some entry in input.aggregate
+		some path in entry.aggregate_data.entrypoints
+	}
+
+	some entry in input.aggregate
+	some rule in entry.aggregate_data.rules
+
+	not rule.path in all_ref_prefixes
+	not _any_prefix_in(rule.path, all_refs)
+	not _any_prefix_in(rule.path, all_entrypoints)
+
+	violation := result.fail(rego.metadata.chain(), {"location": rule.location})
+}
+
+# the paths referenced in the input module, resolved to their full path (without the data prefix),
+# including refs to rules or functions in the same package, imported refs and `with` targets
+_referenced_paths contains path if {
+	some rule in input.rules
+
+	walk(rule, [location, value])
+
+	not _head_ref_location(location)
+
+	some path in _resolve(_ref_terms(rule, location, value), package_path, ast.resolved_imports)
+}
+
+_ref_terms(_, _, value) := value.value if value.type == "ref"
+
+_ref_terms(rule, location, value) := [value] if {
+	value.type == "var"
+	not _in_ref(rule, location)
+}
+
+# vars in refs are resolved as part of the ref
+_in_ref(rule, location) if {
+	count(location) > 2
+	location[count(location) - 2] == "value"
+
+	object.get(rule, array.slice(location, 0, count(location) - 2), {}).type == "ref"
+}
+
+_head_ref_location(location) if {
+	some i, key in location
+	key == "head"
+	location[i + 1] in {"ref", "name"}
+}
+
+_resolve(terms, _, _) := {path} if {
+	terms[0].value == "data"
+
+	path := _static_path(array.slice(terms, 1, count(terms)))
+}
+
+_resolve(terms, _, imports) := {path} if {
+	imported := imports[terms[0].value]
+
+	path := array.concat(
+		array.slice(imported, 1, count(imported)),
+		_static_path(array.slice(terms, 1, count(terms))),
+	)
+}
+
+# refs not starting with data or an import may be a reference to a rule in the same package
+_resolve(terms, pkg_path, imports) := {array.concat(pkg_path, _static_path(terms))} if {
+	not terms[0].value in {"data", "input"}
+	not imports[terms[0].value]
+}
+
+# the static (i.e. string) parts of a ref up until the first var or other dynamic part,
+# where only the first part is allowed to be a var, as in the name of a rule
+_static_path(terms) := [part.value |
+	some i, part in terms
+	i < _first_dynamic(terms)
+]
+
+_first_dynamic(terms) := min({i |
+	some i, part in terms
+	not _static_part(i, part)
+} | {count(terms)})
+
+_static_part(0, part) if part.type in {"var", "string"}
+
+_static_part(i, part) if {
+	i > 0
+	part.type == "string"
+}
+
+# a package or subpackages scoped entrypoint annotation means that any rule in the package is an entrypoint
+_entrypoints contains package_path if {
+	some annotation in input.annotations
+
+	annotation.entrypoint == true
+	annotation.scope in {"package", "subpackages"}
+}
+
+_entrypoints contains path if {
+	some rule in input.rules
+	some annotation in rule.annotations
+
+	annotation.entrypoint == true
+
+	path := array.concat(package_path, _static_path(rule.head.ref))
+}
+
+_any_prefix_in(path, paths) if {
+	some prefix in util.all_paths(path)
+	prefix in paths
+}
+
+# empty paths, like from referencing data itself, mean that everything is referenced
+_any_prefix_in(_, paths) if [] in paths
+
+_test_rule(rule) if startswith(ast.ref_to_string(rule.head.ref), "test_")
+
+_test_rule(rule) if startswith(ast.ref_to_string(rule.head.ref), "todo_test_")
+
+_excepted_package(path) if {
+	some pattern in cfg["except-packages"]
+	glob.match(pattern, ["."], concat(".", path))
+}
+
+_excepted_rule(path) if {
+	some pattern in cfg["except-rules"]
+	glob.match(pattern, ["."], concat(".", path))
+}
@@ -0,0 +1,149 @@
+package regal.rules.bugs["unused-rule_test"]
+
+import rego.v1
+
+import data.regal.config
+
+import data.regal.rules.bugs["unused-rule"] as rule
+
+test_fail_unused_rule_and_function if {
+	agg := rule.aggregate with input as regal.parse_module("p.rego", `package p
+
+import rego.v1
+
+# METADATA
+# entrypoint: true
+allow if {
+	used
+	used_function(input.x)
+}
+
+used := true
+
+used_function(x) := x
+
+unused := true
+
+unused_function(x) := x
+`)
+
+	r := rule.aggregate_report with input as {"aggregate": agg}
+	r == {
+		with_location({"file": "p.rego", "row": 16, "col": 1, "text": "unused := true"}),
+		with_location({"file": "p.rego", "row": 18, "col": 1, "text": "unused_function(x) := x"}),
+	}
+}
+
+test_fail_unused_rule_across_files if {
+	agg1 := rule.aggregate with input as regal.parse_module("p1.rego", `package foo
+
+import data.bar
+
+# METADATA
+# entrypoint: true
+allow := bar.used
+`)
+	agg2 := rule.aggregate with input as regal.parse_module("p2.rego", `package bar
+
+used := 1
+
+unused := 2
+`)
+
+	r := rule.aggregate_report with input as {"aggregate": (agg1 | agg2)}
+	r == {with_location({"file": "p2.rego", "row": 5, "col": 1, "text": "unused := 2"})}
+}
+
+test_success_rules_referenced_by_tests_and_with if {
+	agg1 := rule.aggregate with input as regal.parse_module("p.rego", `package p
+
+import rego.v1
+
+checked if input.x
+
+mocked(x) := x
+`)
+	agg2 := rule.aggregate with input as regal.parse_module("p_test.rego", `package p_test
+
+import rego.v1
+
+import data.p
+
+test_checked if {
+	p.checked with data.p.mocked as mock
+}
+
+mock(_) := true
+`)
+
+	r := rule.aggregate_report with input as {"aggregate": (agg1 | agg2)}
+	r == set()
+}
+
+test_success_rules_referenced_by_prefix if {
+	agg1 := rule.aggregate with input as regal.parse_module("p1.rego", `package foo
+
+import rego.v1
+
+# METADATA
+# entrypoint: true
+allow if data.bar.rules.one
+
+# METADATA
+# entrypoint: true
+deny if data.baz[input.name]
+`)
+	agg2 := rule.aggregate with input as regal.parse_module("p2.rego", `package bar
+
+rules.one := true
+
+rules.two := true
+`)
+	agg3 := rule.aggregate with input as regal.parse_module("p3.rego", `package baz
+
+a := 1
+`)
+
+	r := rule.aggregate_report with input as {"aggregate": ((agg1 | agg2) | agg3)}
+	r == {with_location({"file": "p2.rego", "row": 5, "col": 1, "text": "rules.two := true"})}
+}
+
+test_success_package_entrypoint if {
+	agg := rule.aggregate with input as regal.parse_module("p.rego", `# METADATA
+# entrypoint: true
+package p
+
+allow := true
+`)
+
+	r := rule.aggregate_report with input as {"aggregate": agg}
+	r == set()
+}
+
+test_success_excepted_packages_and_rules if {
+	agg1 := rule.aggregate with input as regal.parse_module("p1.rego", `package policy.main
+
+allow := true
+`)
+		with config.for_rule as {"level": "error", "except-packages": ["policy.*"], "except-rules": []}
+	agg2 := rule.aggregate with input as regal.parse_module("p2.rego", `package other
+
+deny := true
+`)
+		with config.for_rule as {"level": "error", "except-packages": [], "except-rules": ["**.deny"]}
+
+	r := rule.aggregate_report with input as {"aggregate": (agg1 | agg2)}
+	r == set()
+}
+
+with_location(location) := {
+	"category": "bugs",
+	"description": "Rule or function never referenced",
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": config.docs.resolve_url("$baseUrl/$category/unused-rule", "bugs"),
+	}],
+	"title": "unused-rule",
+}
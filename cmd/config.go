@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/linter"
+)
+
+type configShowCommandParams struct {
+	configFile      string
+	format          string
+	disable         repeatedStringFlag
+	disableAll      bool
+	disableCategory repeatedStringFlag
+	enable          repeatedStringFlag
+	enableAll       bool
+	enableCategory  repeatedStringFlag
+	ignoreFiles     repeatedStringFlag
+}
+
+type configValidateCommandParams struct {
+	configFile string
+	format     string
+	rules      repeatedStringFlag
+}
+
+func init() {
+	configCommand := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect Regal configuration",
+		Long:  "Inspect the configuration used by Regal.",
+	}
+
+	params := &configShowCommandParams{}
+
+	configShowCommand := &cobra.Command{
+		Use:   "show [path]",
+		Short: "Show the effective configuration of a file or directory",
+		Long: `Show the effective configuration used when linting a file, or the files of a directory.
+
+The configuration shown is the configuration provided by Regal, merged with all configuration files applying
+to the file (including any configurations extended and overrides), and the enable/disable flags provided, just
+like when linting. Each setting is shown along with its source, i.e. the configuration provided by Regal, the
+configuration file or flag setting it, or the category or global default setting the level of a rule.
+
+If no path is provided, the configuration of the current directory is shown.`,
+
+		Args: cobra.MaximumNArgs(1),
+
+		PreRunE: func(*cobra.Command, []string) error {
+			if params.format != formatYAML && params.format != formatJSON {
+				return fmt.Errorf("format must be %s or %s, got %s", formatYAML, formatJSON, params.format)
+			}
+
+			return nil
+		},
+
+		RunE: wrapProfiling(func(args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			if err := showConfig(params, path, os.Stdout); err != nil {
+				log.SetOutput(os.Stderr)
+				log.Println(err)
+
+				return exit(1)
+			}
+
+			return nil
+		}),
+	}
+
+	configShowCommand.Flags().StringVarP(&params.configFile, "config-file", "c", "",
+		"set path of configuration file")
+	configShowCommand.Flags().StringVarP(&params.format, "format", "f", formatYAML,
+		"set output format (yaml, json)")
+
+	configShowCommand.Flags().VarP(&params.disable, "disable", "d",
+		"disable specific rule(s). This flag can be repeated.")
+	configShowCommand.Flags().BoolVarP(&params.disableAll, "disable-all", "D", false,
+		"disable all rules")
+	configShowCommand.Flags().VarP(&params.disableCategory, "disable-category", "",
+		"disable all rules in a category. This flag can be repeated.")
+
+	configShowCommand.Flags().VarP(&params.enable, "enable", "e",
+		"enable specific rule(s). This flag can be repeated.")
+	configShowCommand.Flags().BoolVarP(&params.enableAll, "enable-all", "E", false,
+		"enable all rules")
+	configShowCommand.Flags().VarP(&params.enableCategory, "enable-category", "",
+		"enable all rules in a category. This flag can be repeated.")
+
+	configShowCommand.Flags().VarP(&params.ignoreFiles, "ignore-files", "",
+		"ignore all files matching a glob-pattern. This flag can be repeated.")
+
+	validateParams := &configValidateCommandParams{}
+
+	configValidateCommand := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Validate the configuration files applying to a file or directory",
+		Long: `Validate the configuration files used when linting a file, or the files of a directory.
+
+Each configuration file is checked for unknown keys, categories and rules, invalid levels, and attributes of
+rules with values of the wrong type. Besides the built-in rules, custom rules found in the .regal/rules directory
+next to each configuration file, or provided using the --rules flag, are known. Each error found is reported along
+with its location in the configuration file.
+
+If no path is provided, the configuration files applying to the current directory are validated.`,
+
+		Args: cobra.MaximumNArgs(1),
+
+		PreRunE: func(*cobra.Command, []string) error {
+			if validateParams.format != formatPretty && validateParams.format != formatJSON {
+				return fmt.Errorf("format must be %s or %s, got %s", formatPretty, formatJSON, validateParams.format)
+			}
+
+			return nil
+		},
+
+		RunE: wrapProfiling(func(args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			errs, err := validateConfig(validateParams, path)
+			if err != nil {
+				log.SetOutput(os.Stderr)
+				log.Println(err)
+
+				return exit(1)
+			}
+
+			if err := writeValidationErrors(validateParams.format, errs, os.Stdout); err != nil {
+				log.SetOutput(os.Stderr)
+				log.Println(err)
+
+				return exit(1)
+			}
+
+			if len(errs) > 0 {
+				return exit(1)
+			}
+
+			return nil
+		}),
+	}
+
+	configValidateCommand.Flags().StringVarP(&validateParams.configFile, "config-file", "c", "",
+		"set path of configuration file")
+	configValidateCommand.Flags().StringVarP(&validateParams.format, "format", "f", formatPretty,
+		"set output format (pretty, json)")
+	configValidateCommand.Flags().VarP(&validateParams.rules, "rules", "r",
+		"set custom rules file(s). This flag can be repeated.")
+
+	configCommand.AddCommand(configShowCommand)
+	configCommand.AddCommand(configValidateCommand)
+	RootCommand.AddCommand(configCommand)
+}
+
+func showConfig(params *configShowCommandParams, path string, out io.Writer) error {
+	resolver := config.NewResolver()
+
+	if params.configFile != "" {
+		if _, err := os.Stat(params.configFile); err != nil {
+			return fmt.Errorf("user-provided config file not found: %w", err)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		resolver = config.NewResolverForConfigFile(params.configFile, cwd)
+	}
+
+	layers, err := resolver.Layers(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	regal := linter.NewLinter().
+		WithDisableAll(params.disableAll).
+		WithDisabledCategories(params.disableCategory.v...).
+		WithDisabledRules(params.disable.v...).
+		WithEnableAll(params.enableAll).
+		WithEnabledCategories(params.enableCategory.v...).
+		WithEnabledRules(params.enable.v...).
+		WithIgnore(params.ignoreFiles.v)
+
+	explanation, err := regal.ExplainConfig(layers)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	explanation.File = filepath.Clean(path)
+
+	switch params.format {
+	case formatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(explanation)
+	case formatYAML:
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+
+		err = errors.Join(encoder.Encode(explanation), encoder.Close())
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// validateConfig validates the config files applying to path, or the config file provided, against the built-in
+// rules and the custom rules of each config file, if any, and those provided.
+func validateConfig(params *configValidateCommandParams, path string) (config.ValidationErrors, error) {
+	files := []string{params.configFile}
+
+	if params.configFile == "" {
+		dir := path
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+
+		files = config.FindConfigFiles(dir)
+	}
+
+	errs := make(config.ValidationErrors, 0)
+
+	for _, file := range files {
+		customRules := params.rules.v
+
+		// custom rules in the .regal directory of the config file
+		rulesDir := filepath.Join(filepath.Dir(file), "rules")
+		if info, err := os.Stat(rulesDir); err == nil && info.IsDir() && params.configFile == "" {
+			customRules = append(slices.Clone(customRules), rulesDir)
+		}
+
+		regal := linter.NewLinter()
+		if len(customRules) > 0 {
+			regal = regal.WithCustomRules(customRules)
+		}
+
+		known, err := regal.KnownRules(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rules: %w", err)
+		}
+
+		fileErrs, err := config.ValidateFile(file, known)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		errs = append(errs, fileErrs...)
+	}
+
+	return errs, nil
+}
+
+func writeValidationErrors(format string, errs config.ValidationErrors, out io.Writer) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(errs); err != nil {
+			return fmt.Errorf("failed to write validation errors: %w", err)
+		}
+
+		return nil
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(out, err.Error())
+	}
+
+	if len(errs) > 0 {
+		fmt.Fprintf(out, "\n%d error(s) found\n", len(errs))
+	}
+
+	return nil
+}
@@ -15,4 +15,14 @@ const (
 	formatSarif = "sarif"
 	// formatJunit is the JUnit format value for the --format flag in various commands.
 	formatJunit = "junit"
+	// formatCheckstyle is the Checkstyle XML format value for the --format flag in various commands.
+	formatCheckstyle = "checkstyle"
+	// formatCodeClimate is the Code Climate JSON format value for the --format flag in various commands.
+	formatCodeClimate = "codeclimate"
+	// formatHTML is the HTML format value for the --format flag in various commands.
+	formatHTML = "html"
+	// formatYAML is the YAML format value for the --format flag in various commands.
+	formatYAML = "yaml"
+	// formatTemplate is the template format value for the --format flag in various commands.
+	formatTemplate = "template"
 )
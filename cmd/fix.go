@@ -1,18 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/format"
@@ -43,6 +44,8 @@ type fixCommandParams struct {
 	outputFile      string
 	rules           repeatedStringFlag
 	timeout         time.Duration
+	watch           bool
+	stdinFilename   string
 }
 
 func (p *fixCommandParams) getConfigFile() string {
@@ -60,7 +63,9 @@ func init() {
 Note that this command is intended to help fix style-related issues,
 and could be considered as a stricter opa fmt command.
 Issues like bugs should be fixed manually,
-as it is important to understand why the were flagged.`, "\n", " ")
+as it is important to understand why the were flagged.`, "\n", " ") + `
+
+Provide - as the path to fix policy read from stdin, and have the fixed policy written to stdout.`
 
 	fixCommand := &cobra.Command{
 		Use:   "fix <path> [path [...]]",
@@ -86,11 +91,22 @@ The linter rules with automatic fixes available are currently:
 				return errors.New("at least one file or directory must be provided for fixing")
 			}
 
+			if stdin, err := readsStdin(args); err != nil {
+				return err
+			} else if stdin && params.watch {
+				return errors.New("--watch can't be used when reading from stdin")
+			}
+
 			return nil
 		},
 
 		RunE: wrapProfiling(func(args []string) error {
-			err := fix(args, params)
+			run := fix
+			if params.watch {
+				run = fixWatch
+			}
+
+			err := run(args, params)
 			if err != nil {
 				log.SetOutput(os.Stderr)
 				log.Println(err)
@@ -134,6 +150,12 @@ The linter rules with automatic fixes available are currently:
 	fixCommand.Flags().VarP(&params.ignoreFiles, "ignore-files", "",
 		"ignore all files matching a glob-pattern. This flag can be repeated.")
 
+	fixCommand.Flags().StringVar(&params.stdinFilename, "stdin-filename", "",
+		"set filename to use for policy read from stdin, which determines config and ignore patterns used (default stdin.rego)")
+
+	fixCommand.Flags().BoolVar(&params.watch, "watch", false,
+		"watch input paths and configuration for changes, and fix again whenever changes are made")
+
 	addPprofFlag(fixCommand.Flags())
 
 	RootCommand.AddCommand(fixCommand)
@@ -160,6 +182,182 @@ func fix(args []string, params *fixCommandParams) error {
 		}
 	}
 
+	setup, err := setupFixer(args, params)
+	if err != nil {
+		return err
+	}
+
+	if stdin, _ := readsStdin(args); stdin {
+		// the fixed policy is written to stdout, so the report goes elsewhere
+		if params.outputFile == "" {
+			outputWriter = os.Stderr
+		}
+
+		return fixStdin(ctx, setup, params, outputWriter)
+	}
+
+	_, err = runFix(ctx, setup, params.format, outputWriter, fileprovider.NewFSFileProvider(setup.ignore, args...))
+
+	return err
+}
+
+// fixStdin fixes policy read from stdin, and writes the fixed policy to stdout.
+func fixStdin(ctx context.Context, setup *fixSetup, params *fixCommandParams, outputWriter io.Writer) error {
+	filename, content, err := readStdin(params.stdinFilename)
+	if err != nil {
+		return err
+	}
+
+	filtered, err := config.FilterIgnoredPaths([]string{filename}, setup.ignore, false, "")
+	if err != nil {
+		return fmt.Errorf("failed to filter ignored paths: %w", err)
+	}
+
+	// ignored files are written back unchanged
+	if len(filtered) == 0 {
+		_, err = io.WriteString(os.Stdout, content)
+
+		return err //nolint:wrapcheck
+	}
+
+	fileProvider := fileprovider.NewInMemoryFileProvider(map[string][]byte{filename: []byte(content)})
+
+	if _, err = runFix(ctx, setup, params.format, outputWriter, fileProvider); err != nil {
+		return err
+	}
+
+	fixed, err := fileProvider.GetFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to get fixed policy: %w", err)
+	}
+
+	_, err = os.Stdout.Write(fixed)
+
+	return err //nolint:wrapcheck
+}
+
+// fixWatch fixes the files provided, and then again every time any of them, or the configuration, changes.
+// Only the files changed are fixed on subsequent runs.
+func fixWatch(args []string, params *fixCommandParams) error {
+	ctx, stop := watchContext()
+	defer stop()
+
+	if params.noColor {
+		color.NoColor = true
+	}
+
+	setup, err := setupFixer(args, params)
+	if err != nil {
+		return err
+	}
+
+	// the contents of files last written by the fixer, so that the changes made by fixing
+	// a file don't trigger the file to be fixed again
+	written := make(map[string]string)
+
+	fixAndReport := func(roots []string) {
+		runCtx, cancel := withTimeout(ctx, params.timeout)
+		defer cancel()
+
+		outputWriter := io.Writer(os.Stdout)
+
+		if params.outputFile != "" {
+			w, err := getWriterForOutputFile(params.outputFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to open output file before use %v\n", err)
+
+				return
+			}
+
+			if f, ok := w.(*os.File); ok {
+				defer rio.CloseFileIgnore(f)
+			}
+
+			outputWriter = w
+		}
+
+		fixReport, err := runFix(runCtx, setup, params.format, outputWriter,
+			fileprovider.NewFSFileProvider(setup.ignore, roots...))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return
+		}
+
+		for _, file := range fixReport.FixedFiles() {
+			if bs, err := os.ReadFile(file); err == nil {
+				if abs, err := filepath.Abs(file); err == nil {
+					written[abs] = string(bs)
+				}
+			}
+		}
+	}
+
+	fixAndReport(args)
+
+	paths := append(slices.Clone(args), setup.configPaths...)
+	relevant := relevantForWatch(setup.configPaths)
+
+	return watchChanges(ctx, paths, func(path string) bool {
+		if content, ok := written[path]; ok {
+			delete(written, path)
+
+			if bs, err := os.ReadFile(path); err == nil && string(bs) == content {
+				return false
+			}
+		}
+
+		return relevant(path)
+	}, func(changed []string) []string {
+		roots := args
+
+		if isConfigChange(changed, setup.configPaths) {
+			newSetup, err := setupFixer(args, params)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return nil
+			}
+
+			setup = newSetup
+		} else {
+			roots = make([]string, 0, len(changed))
+			cwd, _ := os.Getwd()
+
+			for _, path := range changed {
+				if _, err := os.Stat(path); err != nil {
+					continue
+				}
+
+				if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+					path = rel
+				}
+
+				roots = append(roots, path)
+			}
+
+			if len(roots) == 0 {
+				return nil
+			}
+		}
+
+		fixAndReport(roots)
+
+		return nil
+	})
+}
+
+// fixSetup is a linter and the files to ignore, configured from the command line parameters and
+// the user config file.
+type fixSetup struct {
+	linter linter.Linter
+	ignore []string
+	// configPaths are the config file and custom rules paths used to set up the linter,
+	// which if changed require the linter to be set up again
+	configPaths []string
+}
+
+func setupFixer(args []string, params *fixCommandParams) (*fixSetup, error) {
 	var regalDir *os.File
 
 	var customRulesDir string
@@ -168,12 +366,20 @@ func fix(args []string, params *fixCommandParams) error {
 
 	cwd, _ := os.Getwd()
 
-	if len(args) == 1 {
+	stdin, err := readsStdin(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case stdin:
+		configSearchPath = configSearchPathForStdin(cwd, params.stdinFilename)
+	case len(args) == 1:
 		configSearchPath = args[0]
 		if !strings.HasPrefix(args[0], "/") {
 			configSearchPath = filepath.Join(cwd, args[0])
 		}
-	} else {
+	default:
 		configSearchPath, _ = os.Getwd()
 	}
 
@@ -189,6 +395,8 @@ func fix(args []string, params *fixCommandParams) error {
 		}
 	}
 
+	setup := &fixSetup{}
+
 	l := linter.NewLinter().
 		WithDisableAll(params.disableAll).
 		WithDisabledCategories(params.disableCategory.v...).
@@ -200,16 +408,22 @@ func fix(args []string, params *fixCommandParams) error {
 
 	if customRulesDir != "" {
 		l = l.WithCustomRules([]string{customRulesDir})
+		setup.configPaths = append(setup.configPaths, customRulesDir)
 	}
 
 	if params.rules.isSet {
 		l = l.WithCustomRules(params.rules.v)
+		setup.configPaths = append(setup.configPaths, localPaths(params.rules.v)...)
 	}
 
 	if params.ignoreFiles.isSet {
 		l = l.WithIgnore(params.ignoreFiles.v)
 	}
 
+	if params.watch {
+		l = l.WithQueryReuse(true)
+	}
+
 	var userConfig config.Config
 
 	userConfigFile, err := readUserConfig(params, regalDir)
@@ -222,24 +436,48 @@ func fix(args []string, params *fixCommandParams) error {
 			log.Printf("found user config file: %s", userConfigFile.Name())
 		}
 
-		err := yaml.NewDecoder(userConfigFile).Decode(&userConfig)
+		setup.configPaths = append(setup.configPaths, userConfigFile.Name())
+
+		err := config.Decode(userConfigFile, &userConfig)
 		if errors.Is(err, io.EOF) {
 			log.Printf("user config file %q is empty, will use the default config", userConfigFile.Name())
 		} else if err != nil {
 			if regalDir != nil {
-				return fmt.Errorf("failed to decode user config from %s: %w", regalDir.Name(), err)
+				return nil, fmt.Errorf("failed to decode user config from %s: %w", regalDir.Name(), err)
 			}
 
-			return fmt.Errorf("failed to decode user config: %w", err)
+			return nil, fmt.Errorf("failed to decode user config: %w", err)
 		}
 
 		l = l.WithUserConfig(userConfig)
 	case params.configFile != "":
-		return fmt.Errorf("user-provided config file not found: %w", err)
+		return nil, fmt.Errorf("user-provided config file not found: %w", err)
 	case params.debug:
 		log.Println("no user-provided config file found, will use the default config")
 	}
 
+	if regalDir != nil && !slices.Contains(setup.configPaths, filepath.Join(regalDir.Name(), "config.yaml")) {
+		// watch for a config file being created, too
+		setup.configPaths = append(setup.configPaths, filepath.Join(regalDir.Name(), "config.yaml"))
+	}
+
+	setup.linter = l
+	setup.ignore = userConfig.Ignore.Files
+
+	if len(params.ignoreFiles.v) > 0 {
+		setup.ignore = params.ignoreFiles.v
+	}
+
+	return setup, nil
+}
+
+func runFix(
+	ctx context.Context,
+	setup *fixSetup,
+	outputFormat string,
+	outputWriter io.Writer,
+	fileProvider fileprovider.FileProvider,
+) (*fixer.Report, error) {
 	f := fixer.NewFixer()
 	f.RegisterFixes(fixes.NewDefaultFixes()...)
 	f.RegisterMandatoryFixes(
@@ -251,28 +489,20 @@ func fix(args []string, params *fixCommandParams) error {
 		},
 	)
 
-	ignore := userConfig.Ignore.Files
-
-	if len(params.ignoreFiles.v) > 0 {
-		ignore = params.ignoreFiles.v
-	}
-
-	fileProvider := fileprovider.NewFSFileProvider(ignore, args...)
-
-	fixReport, err := f.Fix(ctx, &l, fileProvider)
+	fixReport, err := f.Fix(ctx, &setup.linter, fileProvider)
 	if err != nil {
-		return fmt.Errorf("failed to fix: %w", err)
+		return nil, fmt.Errorf("failed to fix: %w", err)
 	}
 
-	r, err := fixer.ReporterForFormat(params.format, outputWriter)
+	r, err := fixer.ReporterForFormat(outputFormat, outputWriter)
 	if err != nil {
-		return fmt.Errorf("failed to create reporter for format %s: %w", params.format, err)
+		return nil, fmt.Errorf("failed to create reporter for format %s: %w", outputFormat, err)
 	}
 
 	err = r.Report(fixReport)
 	if err != nil {
-		return fmt.Errorf("failed to output fix report: %w", err)
+		return nil, fmt.Errorf("failed to output fix report: %w", err)
 	}
 
-	return nil
+	return fixReport, nil
 }
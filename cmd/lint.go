@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,36 +10,46 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/jstemmer/go-junit-report/v2/junit"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/keys"
 	"github.com/open-policy-agent/opa/metrics"
 	"github.com/open-policy-agent/opa/topdown"
 
 	rbundle "github.com/styrainc/regal/bundle"
+	"github.com/styrainc/regal/internal/git"
 	rio "github.com/styrainc/regal/internal/io"
 	regalmetrics "github.com/styrainc/regal/internal/metrics"
 	"github.com/styrainc/regal/internal/update"
+	"github.com/styrainc/regal/pkg/baseline"
 	"github.com/styrainc/regal/pkg/config"
 	"github.com/styrainc/regal/pkg/linter"
 	"github.com/styrainc/regal/pkg/report"
 	"github.com/styrainc/regal/pkg/reporter"
+	"github.com/styrainc/regal/pkg/rules"
 	"github.com/styrainc/regal/pkg/version"
 )
 
 type lintCommandParams struct {
 	timeout         time.Duration
 	configFile      string
-	format          string
+	format          repeatedStringFlag
 	outputFile      string
 	failLevel       string
 	rules           repeatedStringFlag
+	verificationKey string
+	verificationID  string
+	signingAlg      string
+	scope           string
+	excludeVerify   repeatedStringFlag
 	noColor         bool
 	debug           bool
 	enablePrint     bool
@@ -51,6 +62,16 @@ type lintCommandParams struct {
 	enableAll       bool
 	enableCategory  repeatedStringFlag
 	ignoreFiles     repeatedStringFlag
+	cache           bool
+	cacheDir        string
+	baseline        string
+	baselineWrite   string
+	changedSince    string
+	watch           bool
+	stdinFilename   string
+	templateFile    string
+	codeFrames      bool
+	groupBy         string
 }
 
 func (p *lintCommandParams) getConfigFile() string {
@@ -89,13 +110,36 @@ func init() {
 	lintCommand := &cobra.Command{
 		Use:   "lint <path> [path [...]]",
 		Short: "Lint Rego source files",
-		Long:  `Lint Rego source files for linter rule violations.`,
+		Long: `Lint Rego source files for linter rule violations.
+
+Provide - as the path to lint policy read from stdin.`,
 
 		PreRunE: func(_ *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return errors.New("at least one file or directory must be provided for linting")
 			}
 
+			if stdin, err := readsStdin(args); err != nil {
+				return err
+			} else if stdin && params.watch {
+				return errors.New("--watch can't be used when reading from stdin")
+			}
+
+			if !slices.Contains(config.ReportedLevels, params.failLevel) {
+				return fmt.Errorf("invalid --fail-level %q, must be one of %s",
+					params.failLevel, strings.Join(config.ReportedLevels, ", "))
+			}
+
+			if params.groupBy != "" && params.groupBy != reporter.GroupByFile && params.groupBy != reporter.GroupByRule {
+				return fmt.Errorf("invalid --group-by %q, must be either file or rule", params.groupBy)
+			}
+
+			for _, destination := range reportDestinations(params) {
+				if destination.format == formatTemplate && params.templateFile == "" {
+					return errors.New("--template-file must be provided when using the template format")
+				}
+			}
+
 			return nil
 		},
 
@@ -105,6 +149,17 @@ func init() {
 				params.debug = true
 			}
 
+			if params.watch {
+				if err := lintWatch(args, params); err != nil {
+					log.SetOutput(os.Stderr)
+					log.Println(err)
+
+					return exit(1)
+				}
+
+				return nil
+			}
+
 			rep, err := lint(args, params)
 			if err != nil {
 				log.SetOutput(os.Stderr)
@@ -113,30 +168,7 @@ func init() {
 				return exit(1)
 			}
 
-			errorsFound := 0
-			warningsFound := 0
-
-			for _, violation := range rep.Violations {
-				if violation.Level == "error" {
-					errorsFound++
-				} else if violation.Level == "warning" {
-					warningsFound++
-				}
-			}
-
-			exitCode := 0
-
-			if params.failLevel == "error" && errorsFound > 0 {
-				exitCode = 3
-			}
-
-			if params.failLevel == "warning" {
-				if errorsFound > 0 {
-					exitCode = 3
-				} else if warningsFound > 0 {
-					exitCode = 2
-				}
-			}
+			exitCode := exitCodeForReport(rep, params.failLevel)
 			if exitCode != 0 {
 				return exit(exitCode)
 			}
@@ -147,16 +179,35 @@ func init() {
 
 	lintCommand.Flags().StringVarP(&params.configFile, "config-file", "c", "",
 		"set path of configuration file")
-	lintCommand.Flags().StringVarP(&params.format, "format", "f", formatPretty,
-		"set output format (pretty, compact, json, github, sarif)")
+	lintCommand.Flags().VarP(&params.format, "format", "f",
+		"set output format (pretty, compact, json, github, sarif, junit, checkstyle, codeclimate, html, template), "+
+			"optionally followed by =file to write the report in this format to file. "+
+			"This flag can be repeated to output several formats at once. (default pretty)")
+	lintCommand.Flags().StringVar(&params.templateFile, "template-file", "",
+		"set Go template file used to render the report when using the template format")
+	lintCommand.Flags().BoolVar(&params.codeFrames, "code-frames", false,
+		"show the source code surrounding each violation, with the violation underlined (pretty format only)")
+	lintCommand.Flags().StringVar(&params.groupBy, "group-by", "",
+		"group violations by file or rule (pretty format only)")
 	lintCommand.Flags().StringVarP(&params.outputFile, "output-file", "o", "",
-		"set file to use for linting output, defaults to stdout")
+		"set file to use for linting output of formats not given a file of their own, defaults to stdout")
 	lintCommand.Flags().StringVarP(&params.failLevel, "fail-level", "l", "error",
-		"set level at which to fail with a non-zero exit code (error, warning)")
+		"set level at which to fail with a non-zero exit code (error, warning, info, hint)")
 	lintCommand.Flags().BoolVar(&params.noColor, "no-color", false,
 		"Disable color output")
 	lintCommand.Flags().VarP(&params.rules, "rules", "r",
-		"set custom rules file(s). This flag can be repeated.")
+		"set custom rules file(s), .tar.gz bundle(s) or HTTP(S) URL(s) of bundles. This flag can be repeated.")
+	lintCommand.Flags().StringVar(&params.verificationKey, "verification-key", "",
+		"set the secret (HMAC) or path of the PEM file containing the public key (RSA and ECDSA) "+
+			"used to verify custom rules bundles")
+	lintCommand.Flags().StringVar(&params.verificationID, "verification-key-id", "default",
+		"name assigned to the verification key used for custom rules bundle verification")
+	lintCommand.Flags().StringVar(&params.signingAlg, "signing-alg", "RS256",
+		"name of the signing algorithm used to verify custom rules bundles")
+	lintCommand.Flags().StringVar(&params.scope, "scope", "",
+		"scope to use for custom rules bundle signature verification")
+	lintCommand.Flags().Var(&params.excludeVerify, "exclude-files-verify",
+		"set file names to exclude during custom rules bundle verification. This flag can be repeated.")
 	lintCommand.Flags().DurationVar(&params.timeout, "timeout", 0,
 		"set timeout for linting (default unlimited)")
 	lintCommand.Flags().BoolVar(&params.debug, "debug", false,
@@ -185,14 +236,70 @@ func init() {
 	lintCommand.Flags().VarP(&params.ignoreFiles, "ignore-files", "",
 		"ignore all files matching a glob-pattern. This flag can be repeated.")
 
+	lintCommand.Flags().BoolVar(&params.cache, "cache", false,
+		"enable caching of lint results, so that only changed files are re-evaluated")
+	lintCommand.Flags().StringVar(&params.cacheDir, "cache-dir", "",
+		"set directory used for caching lint results (default .regal/cache), implies --cache")
+
+	lintCommand.Flags().StringVar(&params.baseline, "baseline", "",
+		"set baseline file, violations recorded in the baseline will not be reported")
+	lintCommand.Flags().StringVar(&params.baselineWrite, "baseline-write", "",
+		"write all violations found to baseline file, for use with --baseline")
+
+	lintCommand.Flags().StringVar(&params.changedSince, "changed-since", "",
+		"only lint Rego files changed since git ref (e.g. origin/main), reporting violations in changed lines")
+
+	lintCommand.Flags().StringVar(&params.stdinFilename, "stdin-filename", "",
+		"set filename to use for policy read from stdin, which determines config and ignore patterns used (default stdin.rego)")
+
+	lintCommand.Flags().BoolVar(&params.watch, "watch", false,
+		"watch input paths and configuration for changes, and lint again whenever changes are made")
+
 	addPprofFlag(lintCommand.Flags())
 
 	RootCommand.AddCommand(lintCommand)
 }
 
-func lint(args []string, params *lintCommandParams) (report.Report, error) {
-	var err error
+// exitCodeForReport returns the exit code for a lint run, which is 3 if any errors were found, 2 if any other
+// violations at or above the fail level were found, and 0 otherwise. Violations counted against a budget only
+// affect the exit code when the budget is exceeded, in which case the fail level doesn't apply.
+func exitCodeForReport(rep report.Report, failLevel string) int {
+	exitCode := 0
+
+	for _, violation := range rep.Violations {
+		if slices.ContainsFunc(rep.Summary.Budgets, func(b report.Budget) bool { return b.Covers(violation) }) {
+			continue
+		}
+
+		if !config.IsMoreSevereOrEqual(violation.Level, failLevel) {
+			continue
+		}
+
+		if violation.Level == config.LevelError {
+			return 3
+		}
+
+		exitCode = 2
+	}
 
+	for _, budget := range rep.Summary.Budgets {
+		if !budget.Exceeded() {
+			continue
+		}
+
+		for _, violation := range rep.Violations {
+			if budget.Covers(violation) && violation.Level == config.LevelError {
+				return 3
+			}
+		}
+
+		exitCode = 2
+	}
+
+	return exitCode
+}
+
+func lint(args []string, params *lintCommandParams) (report.Report, error) {
 	ctx, cancel := getLinterContext(params)
 	defer cancel()
 
@@ -200,21 +307,135 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 		color.NoColor = true
 	}
 
-	// if an outputFile has been set, open it for writing or create it
-	var outputWriter io.Writer
+	// open the output files before linting, to fail early if they can't be written to
+	reporters, closeOutputs, err := openReporters(params)
+	if err != nil {
+		return report.Report{}, err
+	}
 
-	outputWriter = os.Stdout
-	if params.outputFile != "" {
-		outputWriter, err = getWriterForOutputFile(params.outputFile)
-		if err != nil {
-			return report.Report{}, fmt.Errorf("failed to open output file before use %w", err)
+	defer closeOutputs()
+
+	// regal rules are loaded here and passed to the linter separately
+	// as the configuration is also used to determine feature toggles
+	// and the defaults from the data.yaml here.
+	regalRules := rio.MustLoadRegalBundleFS(rbundle.Bundle)
+
+	setup, err := setupLinter(args, params, regalRules)
+	if err != nil {
+		return report.Report{}, err
+	}
+
+	// the baseline is read before linting, as the same file may be provided for writing
+	base, err := readBaseline(params)
+	if err != nil {
+		return report.Report{}, err
+	}
+
+	go updateCheckAndWarn(params, regalRules, &setup.userConfig)
+
+	result, err := runLint(ctx, setup, params, base)
+	if err != nil {
+		return report.Report{}, err
+	}
+
+	return result, publish(ctx, reporters, result)
+}
+
+// lintWatch lints the files provided, and then again every time any of them, or the configuration, changes.
+// The same linter is reused between runs, and only files changed since the last run are evaluated again,
+// unless the configuration changed, in which case the linter is set up again from scratch.
+func lintWatch(args []string, params *lintCommandParams) error {
+	ctx, stop := watchContext()
+	defer stop()
+
+	if params.noColor {
+		color.NoColor = true
+	}
+
+	regalRules := rio.MustLoadRegalBundleFS(rbundle.Bundle)
+
+	setup, err := setupLinter(args, params, regalRules)
+	if err != nil {
+		return err
+	}
+
+	base, err := readBaseline(params)
+	if err != nil {
+		return err
+	}
+
+	lintAndPublish := func() {
+		runCtx, cancel := withTimeout(ctx, params.timeout)
+		defer cancel()
+
+		if err := lintAndPublishOnce(runCtx, setup, params, base); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 		}
 	}
 
+	lintAndPublish()
+
+	paths := append(slices.Clone(args), setup.configPaths...)
+	paths = append(paths, setup.resolver.ConfigFiles()...)
+
+	return watchChanges(ctx, paths, relevantForWatch(setup.configPaths), func(changed []string) []string {
+		if isConfigChange(changed, setup.configPaths) {
+			newSetup, err := setupLinter(args, params, regalRules)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return nil
+			}
+
+			setup = newSetup
+		}
+
+		lintAndPublish()
+
+		// config files applying to files linted, which may be outside of the paths watched, or extended
+		return setup.resolver.ConfigFiles()
+	})
+}
+
+func lintAndPublishOnce(ctx context.Context, setup *lintSetup, params *lintCommandParams, base *baseline.Baseline) error {
+	result, err := runLint(ctx, setup, params, base)
+	if err != nil {
+		return err
+	}
+
+	// output files are truncated for every run, so that they only ever contain the latest report
+	reporters, closeOutputs, err := openReporters(params)
+	if err != nil {
+		return err
+	}
+
+	defer closeOutputs()
+
+	return publish(ctx, reporters, result)
+}
+
+// lintSetup is a linter configured from the command line parameters and the user config file.
+type lintSetup struct {
+	linter     linter.Linter
+	userConfig config.Config
+	// configPaths are the config file and custom rules paths used to set up the linter,
+	// which if changed require the linter to be set up again
+	configPaths []string
+	// inputPaths are the paths provided to lint, unless policy is read from stdin
+	inputPaths []string
+	// resolver resolves the configuration of each file linted
+	resolver *config.Resolver
+	// knownRules returns the rules known when validating the config file at path
+	knownRules func(path string) (config.KnownRules, error)
+}
+
+func setupLinter(args []string, params *lintCommandParams, regalRules bundle.Bundle) (*lintSetup, error) {
 	var regalDir *os.File
 
 	var customRulesDir string
 
+	var customRules []string
+
 	var configSearchPath string
 
 	cwd, _ := os.Getwd()
@@ -224,12 +445,20 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 		m.Timer(regalmetrics.RegalConfigSearch).Start()
 	}
 
-	if len(args) == 1 {
+	stdin, err := readsStdin(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case stdin:
+		configSearchPath = configSearchPathForStdin(cwd, params.stdinFilename)
+	case len(args) == 1:
 		configSearchPath = args[0]
 		if !strings.HasPrefix(args[0], "/") {
 			configSearchPath = filepath.Join(cwd, args[0])
 		}
-	} else {
+	default:
 		configSearchPath, _ = os.Getwd()
 	}
 
@@ -249,10 +478,7 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 		m.Timer(regalmetrics.RegalConfigSearch).Stop()
 	}
 
-	// regal rules are loaded here and passed to the linter separately
-	// as the configuration is also used to determine feature toggles
-	// and the defaults from the data.yaml here.
-	regalRules := rio.MustLoadRegalBundleFS(rbundle.Bundle)
+	setup := &lintSetup{}
 
 	regal := linter.NewEmptyLinter().
 		WithAddedBundle(regalRules).
@@ -262,19 +488,39 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 		WithEnableAll(params.enableAll).
 		WithEnabledCategories(params.enableCategory.v...).
 		WithEnabledRules(params.enable.v...).
-		WithDebugMode(params.debug).
-		WithInputPaths(args)
+		WithDebugMode(params.debug)
+
+	if !stdin {
+		regal = regal.WithInputPaths(args)
+		setup.inputPaths = args
+	}
 
 	if params.enablePrint {
 		regal = regal.WithPrintHook(topdown.NewPrintHook(os.Stderr))
 	}
 
 	if customRulesDir != "" {
-		regal = regal.WithCustomRules([]string{customRulesDir})
+		customRules = []string{customRulesDir}
+		regal = regal.WithCustomRules(customRules)
+		setup.configPaths = append(setup.configPaths, customRulesDir)
 	}
 
 	if params.rules.isSet {
-		regal = regal.WithCustomRules(params.rules.v)
+		verification, err := params.verificationConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		cacheDir := params.cacheDir
+		if cacheDir == "" {
+			cacheDir = defaultCacheDir(regalDir)
+		}
+
+		customRules = params.rules.v
+		regal = regal.WithCustomRules(customRules).
+			WithCustomRulesVerification(verification).
+			WithCustomRulesCacheDir(filepath.Join(cacheDir, "bundles"))
+		setup.configPaths = append(setup.configPaths, localPaths(params.rules.v)...)
 	}
 
 	if params.ignoreFiles.isSet {
@@ -290,7 +536,29 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 		regal = regal.WithProfiling(true)
 	}
 
-	var userConfig config.Config
+	switch {
+	case params.cache || params.cacheDir != "":
+		cacheDir := params.cacheDir
+		if cacheDir == "" {
+			cacheDir = defaultCacheDir(regalDir)
+		}
+
+		if params.debug {
+			log.Printf("using lint cache in %s", cacheDir)
+		}
+
+		regal = regal.WithCache(linter.NewCache(cacheDir))
+	case params.watch:
+		// when watching, only files changed since the last run need to be linted again
+		regal = regal.WithCache(linter.NewInMemoryCache())
+	}
+
+	if params.watch {
+		regal = regal.WithQueryReuse(true)
+	}
+
+	// config files read are validated against the rules known, so that misspelled rules are warned about
+	setup.knownRules = configKnownRules(regal, customRules)
 
 	userConfigFile, err := readUserConfig(params, regalDir)
 
@@ -302,41 +570,251 @@ func lint(args []string, params *lintCommandParams) (report.Report, error) {
 			log.Printf("found user config file: %s", userConfigFile.Name())
 		}
 
-		err := yaml.NewDecoder(userConfigFile).Decode(&userConfig)
+		setup.configPaths = append(setup.configPaths, userConfigFile.Name())
+
+		err := config.Decode(userConfigFile, &setup.userConfig)
 		if errors.Is(err, io.EOF) {
 			log.Printf("user config file %q is empty, will use the default config", userConfigFile.Name())
 		} else if err != nil {
 			if regalDir != nil {
-				return report.Report{}, fmt.Errorf("failed to decode user config from %s: %w", regalDir.Name(), err)
+				return nil, fmt.Errorf("failed to decode user config from %s: %w", regalDir.Name(), err)
 			}
 
-			return report.Report{}, fmt.Errorf("failed to decode user config: %w", err)
+			return nil, fmt.Errorf("failed to decode user config: %w", err)
 		}
 
-		regal = regal.WithUserConfig(userConfig)
+		regal = regal.WithUserConfig(setup.userConfig)
 	case params.configFile != "":
-		return report.Report{}, fmt.Errorf("user-provided config file not found: %w", err)
+		return nil, fmt.Errorf("user-provided config file not found: %w", err)
 	case params.debug:
 		log.Println("no user-provided config file found, will use the default config")
 	}
 
+	// files may be configured differently by config files nearer to them, or by overrides
+	if params.configFile != "" {
+		setup.resolver = config.NewResolverForConfigFile(params.configFile, cwd)
+	} else {
+		setup.resolver = config.NewResolver()
+	}
+
+	regal = regal.WithConfigResolver(setup.resolver.ForFile)
+
+	if stdin {
+		input, err := readStdinInput(params, setup.userConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		regal = regal.WithInputModules(input)
+	}
+
+	if regalDir != nil && !slices.Contains(setup.configPaths, filepath.Join(regalDir.Name(), "config.yaml")) {
+		// watch for a config file being created, too
+		setup.configPaths = append(setup.configPaths, filepath.Join(regalDir.Name(), "config.yaml"))
+	}
+
 	if params.metrics {
 		m.Timer(regalmetrics.RegalConfigParse).Stop()
 	}
 
-	go updateCheckAndWarn(params, regalRules, &userConfig)
+	setup.linter = regal
+
+	return setup, nil
+}
+
+// configKnownRules returns a function returning the rules known when validating the config file at path, i.e. the
+// rules of regal, along with any custom rules in the .regal directory holding the config file, as with regal config
+// validate. The rules are loaded once for each set of custom rules.
+func configKnownRules(regal linter.Linter, customRules []string) func(string) (config.KnownRules, error) {
+	loaded := make(map[string]config.KnownRules)
+
+	return func(path string) (config.KnownRules, error) {
+		paths := customRules
 
-	result, err := regal.Lint(ctx)
+		rulesDir := filepath.Join(filepath.Dir(path), "rules")
+		if filepath.Base(filepath.Dir(path)) == ".regal" && !slices.Contains(paths, rulesDir) {
+			if info, err := os.Stat(rulesDir); err == nil && info.IsDir() {
+				paths = append(slices.Clone(paths), rulesDir)
+			}
+		}
+
+		// without custom rules, the built-in rules are known without compiling the rules
+		if len(paths) == 0 {
+			return config.BuiltinRules(), nil
+		}
+
+		key := strings.Join(paths, string(os.PathListSeparator))
+		if known, ok := loaded[key]; ok {
+			return known, nil
+		}
+
+		known, err := regal.WithCustomRules(paths).KnownRules(context.Background())
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		loaded[key] = known
+
+		return known, nil
+	}
+}
+
+// warnUnknownRules writes a warning for each category or rule configured in the config files read while linting,
+// but not known to regal. Unlike other errors in config files, these don't stop linting, as the config may be meant
+// for another version of Regal, where the rule exists. Use regal config validate to report them as errors.
+func warnUnknownRules(w io.Writer, setup *lintSetup) {
+	warned := make(map[string]bool)
+
+	for _, path := range setup.resolver.ConfigFiles() {
+		known, err := setup.knownRules(path)
+		if err != nil {
+			fmt.Fprintf(w, "failed to load rules for validating config file %s: %v\n", path, err)
+
+			continue
+		}
+
+		// other errors have already stopped linting, so only unknown rules are reported here
+		errs, err := config.ValidateFile(path, known)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range errs {
+			if e.UnknownRule && !warned[e.Error()] {
+				warned[e.Error()] = true
+
+				fmt.Fprintf(w, "warning: %s\n", e)
+			}
+		}
+	}
+}
+
+// readStdinInput reads and parses the policy provided on stdin. Policy found in an ignored file
+// is not parsed, as it would not be linted anyway.
+func readStdinInput(params *lintCommandParams, userConfig config.Config) (*rules.Input, error) {
+	filename, content, err := readStdin(params.stdinFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	ignore := userConfig.Ignore.Files
+	if params.ignoreFiles.isSet {
+		ignore = params.ignoreFiles.v
+	}
+
+	filtered, err := config.FilterIgnoredPaths([]string{filename}, ignore, false, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter ignored paths: %w", err)
+	}
+
+	if len(filtered) == 0 {
+		input := rules.NewInput(map[string]string{}, map[string]*ast.Module{})
+
+		return &input, nil
+	}
+
+	input, err := rules.InputFromText(filename, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input from stdin: %w", err)
+	}
+
+	return &input, nil
+}
+
+func readBaseline(params *lintCommandParams) (*baseline.Baseline, error) {
+	if params.baseline == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	b, err := baseline.ReadFile(params.baseline)
 	if err != nil {
-		return report.Report{}, formatError(params.format, fmt.Errorf("error(s) encountered while linting: %w", err))
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	return &b, nil
+}
+
+// runLint lints using the linter provided, and applies any filtering of the result requested in params.
+func runLint(
+	ctx context.Context,
+	setup *lintSetup,
+	params *lintCommandParams,
+	base *baseline.Baseline,
+) (report.Report, error) {
+	var changes *git.Changes
+
+	regal := setup.linter
+	skip := false
+
+	if params.changedSince != "" {
+		cwd, _ := os.Getwd()
+
+		var err error
+
+		changes, err = git.ChangedSince(ctx, cwd, params.changedSince)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("failed to determine changes since %s: %w", params.changedSince, err)
+		}
+
+		// only the changed files are linted, while aggregate rules still consider all files provided
+		if setup.inputPaths != nil {
+			changed := changes.Within(setup.inputPaths)
+
+			regal = regal.WithInputPaths(changed).WithAggregateContextPaths(setup.inputPaths)
+			skip = len(changed) == 0
+		}
+	}
+
+	var result report.Report
+
+	if !skip {
+		var err error
+
+		result, err = regal.Lint(ctx)
+		if err != nil {
+			return report.Report{}, formatError(primaryFormat(params),
+				fmt.Errorf("error(s) encountered while linting: %w", err))
+		}
+
+		warnUnknownRules(os.Stderr, setup)
+	}
+
+	if params.baselineWrite != "" {
+		if err := baseline.FromReport(result).WriteFile(params.baselineWrite); err != nil {
+			return report.Report{}, fmt.Errorf("failed to write baseline: %w", err)
+		}
+	}
+
+	if changes != nil {
+		result = filterChanged(result, changes)
+	}
+
+	if base != nil {
+		var stale []baseline.Entry
+
+		result, stale = base.Filter(result)
+
+		if len(stale) > 0 {
+			fmt.Fprintf(os.Stderr,
+				"%d baseline entries no longer match any violation and may be pruned using --baseline-write:\n",
+				len(stale),
+			)
+
+			for _, entry := range stale {
+				fmt.Fprintf(os.Stderr, "- %s\n", entry)
+			}
+		}
 	}
 
-	rep, err := getReporter(params.format, outputWriter)
+	// budgets are counted last, as only violations remaining after filtering count against them
+	budgets, err := linter.ResolvedBudgets(&setup.userConfig, setup.resolver.ForFile, result.Violations)
 	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to get reporter: %w", err)
+		return report.Report{}, fmt.Errorf("failed to count violation budgets: %w", err)
 	}
 
-	return result, rep.Publish(ctx, result) //nolint:wrapcheck
+	result.Summary.Budgets = budgets
+
+	return result, nil
 }
 
 func updateCheckAndWarn(params *lintCommandParams, regalRules bundle.Bundle, userConfig *config.Config) {
@@ -360,10 +838,185 @@ func updateCheckAndWarn(params *lintCommandParams, regalRules bundle.Bundle, use
 	}
 }
 
-func getReporter(format string, outputWriter io.Writer) (reporter.Reporter, error) {
+// filterChanged removes all violations not found on changed lines from the report. Aggregate violations
+// are kept as long as they are reported in a changed file, as the change may be what caused them even
+// if made in a different part of the file.
+func filterChanged(rep report.Report, changes *git.Changes) report.Report {
+	violations := make([]report.Violation, 0, len(rep.Violations))
+
+	for _, violation := range rep.Violations {
+		loc := violation.Location
+
+		switch {
+		case violation.IsAggregate || loc.Row == 0:
+			if !changes.HasFile(loc.File) {
+				continue
+			}
+		case loc.End != nil:
+			if !changes.HasLines(loc.File, loc.Row, loc.End.Row) {
+				continue
+			}
+		default:
+			if !changes.HasLines(loc.File, loc.Row, loc.Row) {
+				continue
+			}
+		}
+
+		violations = append(violations, violation)
+	}
+
+	rep.Violations = violations
+	rep.Summary.NumViolations = len(violations)
+	rep.Summary.FilesFailed = len(rep.ViolationsFileCount())
+
+	return rep
+}
+
+// verificationConfig returns the config used to verify the signatures of custom rules bundles, which like for
+// the OPA commands accepting bundles, are verified only when a verification key is provided.
+func (p *lintCommandParams) verificationConfig() (*bundle.VerificationConfig, error) {
+	if p.verificationKey == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	keyConfig, err := keys.NewKeyConfig(p.verificationKey, p.signingAlg, p.scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification key: %w", err)
+	}
+
+	return bundle.NewVerificationConfig(
+		map[string]*keys.Config{p.verificationID: keyConfig},
+		p.verificationID,
+		p.scope,
+		p.excludeVerify.v,
+	), nil
+}
+
+// localPaths returns the custom rules paths found on the local filesystem, i.e. all but those referring to
+// bundles by URL.
+func localPaths(paths []string) []string {
+	local := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		if !linter.IsCustomRulesURL(path) {
+			local = append(local, path)
+		}
+	}
+
+	return local
+}
+
+// defaultCacheDir returns the cache directory to use when none is provided, which is the cache
+// directory in the .regal directory if found, or the user-wide config directory if not.
+func defaultCacheDir(regalDir *os.File) string {
+	if regalDir != nil {
+		return filepath.Join(regalDir.Name(), "cache")
+	}
+
+	return filepath.Join(config.GlobalDir(), "cache")
+}
+
+// reportDestination is an output format, and the file to write the report in that format to,
+// where an empty file means stdout.
+type reportDestination struct {
+	format string
+	file   string
+}
+
+// reportDestinations returns the destination of each format requested, which are provided either
+// as only the format, with the report written to the output file, or stdout if not set, or on the
+// form format=file, with the report written to file.
+func reportDestinations(params *lintCommandParams) []reportDestination {
+	if !params.format.isSet {
+		return []reportDestination{{format: formatPretty, file: params.outputFile}}
+	}
+
+	destinations := make([]reportDestination, 0, len(params.format.v))
+
+	for _, value := range params.format.v {
+		format, file, ok := strings.Cut(value, "=")
+		if !ok {
+			file = params.outputFile
+		}
+
+		destinations = append(destinations, reportDestination{format: format, file: file})
+	}
+
+	return destinations
+}
+
+// primaryFormat returns the first format requested, which is used for formatting errors.
+func primaryFormat(params *lintCommandParams) string {
+	return reportDestinations(params)[0].format
+}
+
+// openReporters opens the destination of each format requested, and creates a reporter writing to it.
+// The returned function closes all files opened.
+func openReporters(params *lintCommandParams) ([]reporter.Reporter, func(), error) {
+	destinations := reportDestinations(params)
+	reporters := make([]reporter.Reporter, 0, len(destinations))
+	files := make(map[string]io.Writer)
+
+	closeAll := func() {
+		for _, w := range files {
+			if f, ok := w.(*os.File); ok {
+				rio.CloseFileIgnore(f)
+			}
+		}
+	}
+
+	for _, destination := range destinations {
+		var outputWriter io.Writer = os.Stdout
+
+		if destination.file != "" {
+			// the same file may be the destination of more than one format
+			w, ok := files[destination.file]
+			if !ok {
+				var err error
+
+				w, err = getWriterForOutputFile(destination.file)
+				if err != nil {
+					closeAll()
+
+					return nil, nil, fmt.Errorf("failed to open output file before use %w", err)
+				}
+
+				files[destination.file] = w
+			}
+
+			outputWriter = w
+		}
+
+		rep, err := getReporter(destination.format, params, outputWriter)
+		if err != nil {
+			closeAll()
+
+			return nil, nil, fmt.Errorf("failed to get reporter: %w", err)
+		}
+
+		reporters = append(reporters, rep)
+	}
+
+	return reporters, closeAll, nil
+}
+
+// publish publishes the report to all reporters, even if publishing to some of them fails.
+func publish(ctx context.Context, reporters []reporter.Reporter, rep report.Report) error {
+	var errs error
+
+	for _, r := range reporters {
+		errs = errors.Join(errs, r.Publish(ctx, rep))
+	}
+
+	return errs
+}
+
+func getReporter(format string, params *lintCommandParams, outputWriter io.Writer) (reporter.Reporter, error) {
 	switch format {
 	case formatPretty:
-		return reporter.NewPrettyReporter(outputWriter), nil
+		return reporter.NewPrettyReporter(outputWriter).
+			WithCodeFrames(params.codeFrames).
+			WithGroupBy(params.groupBy), nil
 	case formatCompact:
 		return reporter.NewCompactReporter(outputWriter), nil
 	case formatJSON:
@@ -376,6 +1029,14 @@ func getReporter(format string, outputWriter io.Writer) (reporter.Reporter, erro
 		return reporter.NewSarifReporter(outputWriter), nil
 	case formatJunit:
 		return reporter.NewJUnitReporter(outputWriter), nil
+	case formatCheckstyle:
+		return reporter.NewCheckstyleReporter(outputWriter), nil
+	case formatCodeClimate:
+		return reporter.NewCodeClimateReporter(outputWriter), nil
+	case formatHTML:
+		return reporter.NewHTMLReporter(outputWriter), nil
+	case formatTemplate:
+		return reporter.NewTemplateReporterFromFile(outputWriter, params.templateFile)
 	default:
 		return nil, fmt.Errorf("unknown format %s", format)
 	}
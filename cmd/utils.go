@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/styrainc/regal/pkg/config"
@@ -52,3 +55,65 @@ func getLinterContext(params timeoutParams) (context.Context, func()) {
 
 	return ctx, cancel
 }
+
+// stdinPath is the path argument used to read policy from stdin rather than from files.
+const stdinPath = "-"
+
+// defaultStdinFilename is the filename used for policy read from stdin, unless --stdin-filename is provided.
+const defaultStdinFilename = "stdin.rego"
+
+// readsStdin returns true if the args provided mean that the policy should be read from stdin.
+func readsStdin(args []string) (bool, error) {
+	for _, arg := range args {
+		if arg == stdinPath {
+			if len(args) > 1 {
+				return false, errors.New("no other paths may be provided when reading from stdin")
+			}
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// readStdin reads policy from stdin, returning the filename to use for it along with its contents.
+func readStdin(stdinFilename string) (string, string, error) {
+	if stdinFilename == "" {
+		stdinFilename = defaultStdinFilename
+	}
+
+	bs, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read from stdin: %w", err)
+	}
+
+	return stdinFilename, string(bs), nil
+}
+
+// configSearchPathForStdin returns the path to start searching for a .regal directory from, when
+// reading policy from stdin. This is the directory of the filename provided for the policy, or the
+// closest parent directory of it that exists, as the filename need not refer to an existing file.
+func configSearchPathForStdin(cwd, stdinFilename string) string {
+	if stdinFilename == "" {
+		return cwd
+	}
+
+	dir := filepath.Dir(stdinFilename)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return cwd
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	lsconfig "github.com/styrainc/regal/internal/lsp/config"
+)
+
+// watchContext returns a context cancelled when the process is interrupted, which is how
+// watch mode is normally ended.
+func watchContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// watchDebounce is the time to wait for more changes after a change, before running again.
+const watchDebounce = 100 * time.Millisecond
+
+// watchChanges watches paths for changes, and calls run with the changed files each time any of them
+// are found relevant, until ctx is cancelled. Any paths returned by run are watched from then on too,
+// like config files found only while running.
+func watchChanges(ctx context.Context, paths []string, relevant func(string) bool, run func([]string) []string) error {
+	w := lsconfig.NewWatcher(&lsconfig.WatcherOpts{ErrorWriter: os.Stderr})
+
+	if err := w.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start watching for changes: %w", err)
+	}
+
+	if err := w.WatchAll(paths...); err != nil {
+		return fmt.Errorf("failed to start watching for changes: %w", err)
+	}
+
+	fmt.Fprintln(os.Stderr, "Watching for changes (press Ctrl+C to stop)...")
+
+	pending := make(map[string]struct{})
+
+	// nil until a change is seen, and a channel never receiving blocks forever
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case path := <-w.Reload:
+			pending[path] = struct{}{}
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			debounce = nil
+			changed := make([]string, 0, len(pending))
+
+			for path := range pending {
+				if relevant(path) {
+					changed = append(changed, path)
+				}
+			}
+
+			clear(pending)
+
+			if len(changed) == 0 {
+				continue
+			}
+
+			slices.Sort(changed)
+
+			fmt.Fprintf(os.Stderr, "\n[%s] %d file(s) changed, running again\n",
+				time.Now().Format(time.TimeOnly), len(changed))
+
+			if err := w.WatchAll(run(changed)...); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			fmt.Fprintln(os.Stderr, "Watching for changes (press Ctrl+C to stop)...")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// withTimeout returns a context for a single run in watch mode, as the timeout given applies to each
+// run rather than to the whole time spent watching.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// isConfigChange returns true if any of the changed paths is a config file in a .regal directory, or is one of,
+// or found under, configPaths.
+func isConfigChange(changed []string, configPaths []string) bool {
+	for _, path := range changed {
+		if filepath.Base(path) == "config.yaml" && filepath.Base(filepath.Dir(path)) == ".regal" {
+			return true
+		}
+
+		for _, configPath := range configPaths {
+			abs, err := filepath.Abs(configPath)
+			if err != nil {
+				continue
+			}
+
+			if path == abs || strings.HasPrefix(path, abs+string(filepath.Separator)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// relevantForWatch returns a function determining if a changed path should trigger a new run,
+// which is the case for Rego files, config files and the config paths.
+func relevantForWatch(configPaths []string) func(string) bool {
+	return func(path string) bool {
+		return strings.HasSuffix(path, ".rego") || isConfigChange([]string{path}, configPaths)
+	}
+}
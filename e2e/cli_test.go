@@ -9,16 +9,22 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"gopkg.in/yaml.v3"
 
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
 	"github.com/open-policy-agent/opa/tester"
 
 	"github.com/styrainc/regal/internal/testutil"
@@ -177,6 +183,12 @@ func TestLintAllViolations(t *testing.T) {
 		"use-contains":             {},
 		"internal-entrypoint":      {},
 		"file-length":              {},
+		// only reported when all modules compile up to type checking, see TestLintSchemaMismatch
+		"schema-mismatch": {},
+		// only reported when targeting older versions of OPA
+		"unsupported-capability": {},
+		// only reported when all modules compile, which the modules with violations do not
+		"non-indexable-rule": {},
 	}
 
 	for _, category := range cfg.Rules {
@@ -373,6 +385,86 @@ func TestLintRuleNamingConventionFromCustomCategory(t *testing.T) {
 	}
 }
 
+func TestLintWithSignedCustomRulesBundleFromURL(t *testing.T) {
+	t.Parallel()
+
+	cwd := testutil.Must(os.Getwd())(t)
+
+	module := `# METADATA
+# description: Package must be named acme
+package custom.regal.rules.naming["acme-package"]
+
+import rego.v1
+
+import data.regal.result
+
+report contains violation if {
+	input["package"].path[1].value != "acme"
+
+	violation := result.fail(rego.metadata.chain(), result.location(input["package"].path[1]))
+}
+`
+
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Roots: &[]string{"custom/regal/rules/naming"}},
+		Modules: []bundle.ModuleFile{{
+			URL:    "/acme.rego",
+			Path:   "/acme.rego",
+			Raw:    []byte(module),
+			Parsed: ast.MustParseModule(module),
+		}},
+		Data: map[string]any{},
+	}
+
+	if err := b.GenerateSignature(bundle.NewSigningConfig("secret", "HS256", ""), "default", false); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.NewWriter(&buf).Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	lint := func(key string) (*report.Report, *bytes.Buffer, error) {
+		stdout := bytes.Buffer{}
+		stderr := bytes.Buffer{}
+
+		err := regal(&stdout, &stderr)("lint", "--format", "json", "--disable-all", "--enable", "acme-package",
+			"--rules", server.URL+"/bundle.tar.gz", "--verification-key", key, "--signing-alg", "HS256",
+			"--cache-dir", t.TempDir(), cwd+filepath.FromSlash("/testdata/aggregates/two_policies/policy_1.rego"))
+
+		var rep report.Report
+		if stdout.Len() > 0 {
+			if jsonErr := json.Unmarshal(stdout.Bytes(), &rep); jsonErr != nil {
+				t.Fatalf("expected JSON response, got %v", stdout.String())
+			}
+		}
+
+		return &rep, &stderr, err
+	}
+
+	rep, stderr, err := lint("secret")
+
+	expectExitCode(t, err, 3, &bytes.Buffer{}, stderr)
+
+	if len(rep.Violations) != 1 || rep.Violations[0].Title != "acme-package" {
+		t.Errorf("expected acme-package violation, got %v", rep.Violations)
+	}
+
+	_, stderr, err = lint("wrong")
+
+	expectExitCode(t, err, 1, &bytes.Buffer{}, stderr)
+
+	if !strings.Contains(stderr.String(), "failed to load custom rules bundle") {
+		t.Errorf("expected error verifying bundle, got %q", stderr.String())
+	}
+}
+
 func TestAggregatesAreCollectedAndUsed(t *testing.T) {
 	t.Parallel()
 	cwd := testutil.Must(os.Getwd())(t)
@@ -473,6 +565,610 @@ func TestLintAggregateIgnoreDirective(t *testing.T) {
 	}
 }
 
+func TestLintBaseline(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+	policy := filepath.Join(td, "p.rego")
+	baselineFile := filepath.Join(td, "baseline.json")
+
+	write := func(content string) {
+		t.Helper()
+
+		if err := os.WriteFile(policy, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("package p\n\nimport rego.v1\n\ncamelCase := true\n")
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)("lint", "--baseline-write", baselineFile, policy)
+
+	expectExitCode(t, err, 3, &stdout, &stderr)
+
+	// existing violation moved down a few lines, and a new one added
+	write("package p\n\nimport rego.v1\n\n# comment\n\ncamelCase := true\n\nuserName := true\n")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	err = regal(&stdout, &stderr)("lint", "--format", "json", "--baseline", baselineFile, policy)
+
+	expectExitCode(t, err, 3, &stdout, &stderr)
+
+	var rep report.Report
+
+	if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatalf("expected JSON response, got %v", stdout.String())
+	}
+
+	if rep.Summary.NumViolations != 1 || *rep.Violations[0].Location.Text != "userName := true" {
+		t.Errorf("expected only new violation to be reported, got %v", rep.Violations)
+	}
+
+	// with all violations fixed, the baseline entry should be reported as stale
+	write("package p\n\nimport rego.v1\n\nsnake_case := true\n")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	err = regal(&stdout, &stderr)("lint", "--baseline", baselineFile, policy)
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	if !strings.Contains(stderr.String(), "1 baseline entries no longer match any violation") {
+		t.Errorf("expected stale baseline entry to be reported, got %q", stderr.String())
+	}
+}
+
+func TestLintMultipleFormats(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+	sarifFile := filepath.Join(td, "regal.sarif")
+	junitFile := filepath.Join(td, "junit.xml")
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)(
+		"lint", "--format", "json", "--format", "sarif="+sarifFile, "--format", "junit="+junitFile,
+		"testdata/violations",
+	)
+
+	expectExitCode(t, err, 3, &stdout, &stderr)
+
+	var rep report.Report
+
+	if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatalf("expected JSON report on stdout, got %v", stdout.String())
+	}
+
+	bs, err := os.ReadFile(sarifFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(bs), `"version": "2.1.0"`) {
+		t.Errorf("expected SARIF report in %s, got %s", sarifFile, string(bs))
+	}
+
+	bs, err = os.ReadFile(junitFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(bs), `<testsuites name="regal"`) {
+		t.Errorf("expected JUnit report in %s, got %s", junitFile, string(bs))
+	}
+}
+
+func TestLintFailLevel(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	cfg := "rules:\n  style:\n    default:\n      level: ignore\n    prefer-snake-case:\n      level: info\n"
+	if err := os.WriteFile(filepath.Join(td, "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := "package p\n\nimport rego.v1\n\ncamelCase := true\n"
+	if err := os.WriteFile(filepath.Join(td, "p.rego"), []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for failLevel, exitCode := range map[string]int{"error": 0, "warning": 0, "info": 2, "hint": 2} {
+		stdout := bytes.Buffer{}
+		stderr := bytes.Buffer{}
+
+		err := regal(&stdout, &stderr)(
+			"lint", "--format", "json", "--config-file", filepath.Join(td, "config.yaml"),
+			"--fail-level", failLevel, filepath.Join(td, "p.rego"),
+		)
+
+		expectExitCode(t, err, exitCode, &stdout, &stderr)
+
+		var rep report.Report
+
+		if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+			t.Fatalf("expected JSON response, got %v", stdout.String())
+		}
+
+		if len(rep.Violations) != 1 || rep.Violations[0].Level != "info" {
+			t.Errorf("expected a single violation with level info, got %v", rep.Violations)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)("lint", "--fail-level", "critical", filepath.Join(td, "p.rego"))
+
+	expectExitCode(t, err, 1, &stdout, &stderr)
+}
+
+func TestLintBudgets(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	policy := "package p\n\nimport rego.v1\n\ncamelCase := true\n\notherCamelCase := true\n"
+	if err := os.WriteFile(filepath.Join(td, "p.rego"), []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		config   string
+		exitCode int
+	}{
+		// within budget, so the errors don't fail the lint run
+		{"prefer-snake-case:\n      max-violations: 2", 0},
+		// budget exceeded, with the rule at error level
+		{"prefer-snake-case:\n      max-violations: 1", 3},
+		// warnings within the category budget
+		{"default:\n      level: warning\n      max-warnings: 2", 0},
+		// category budget exceeded, failing even though the fail level is error
+		{"default:\n      level: warning\n      max-warnings: 1", 2},
+	} {
+		cfg := "rules:\n  style:\n    " + tc.config + "\n"
+		if err := os.WriteFile(filepath.Join(td, "config.yaml"), []byte(cfg), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		stdout := bytes.Buffer{}
+		stderr := bytes.Buffer{}
+
+		err := regal(&stdout, &stderr)(
+			"lint", "--config-file", filepath.Join(td, "config.yaml"), filepath.Join(td, "p.rego"),
+		)
+
+		expectExitCode(t, err, tc.exitCode, &stdout, &stderr)
+
+		if !strings.Contains(stdout.String(), "Violation budgets:\n") {
+			t.Errorf("expected budgets to be printed for config:\n%s\ngot:\n%s", cfg, stdout.String())
+		}
+	}
+}
+
+func TestLintBudgetsInNestedConfig(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	files := map[string]string{
+		".regal/config.yaml":        "rules: {}\n",
+		"legacy/.regal/config.yaml": "rules:\n  style:\n    prefer-snake-case:\n      max-violations: 1\n",
+		"legacy/p.rego":             "package p\n\nimport rego.v1\n\ncamelCase := true\n",
+	}
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(td, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(td, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	// the violation is within the budget set in the nested config file
+	err := regal(&stdout, &stderr)("lint", filepath.Join(td, "legacy"))
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	if !strings.Contains(stdout.String(), "style/prefer-snake-case: 1 of 1 allowed") {
+		t.Errorf("expected budget from nested config to be printed, got:\n%s", stdout.String())
+	}
+}
+
+func TestLintSchemaMismatch(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	schema := `{"type": "object", "properties": {"user": {"type": "string"}}, "additionalProperties": false}`
+	if err := os.WriteFile(filepath.Join(td, "request.json"), []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := "schemas:\n  directory: " + td + "\n  input:\n    - package: authz\n      schema: request\n"
+	if err := os.WriteFile(filepath.Join(td, "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := "package authz\n\nimport rego.v1\n\nallow if input.users == \"admin\"\n"
+	if err := os.WriteFile(filepath.Join(td, "p.rego"), []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)(
+		"lint", "--format", "json", "--config-file", filepath.Join(td, "config.yaml"), filepath.Join(td, "p.rego"),
+	)
+
+	expectExitCode(t, err, 3, &stdout, &stderr)
+
+	var rep report.Report
+
+	if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatalf("expected JSON response, got %v", stdout.String())
+	}
+
+	if len(rep.Violations) != 1 || rep.Violations[0].Title != "schema-mismatch" {
+		t.Fatalf("expected a single schema-mismatch violation, got %v", rep.Violations)
+	}
+
+	if exp, got := "rego_type_error: undefined ref: input.users", rep.Violations[0].Description; exp != got {
+		t.Errorf("expected description %q, got %q", exp, got)
+	}
+}
+
+func TestLintNestedConfig(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	rootConfig := `rules:
+  style:
+    todo-comment:
+      level: error
+overrides:
+  "gen/**":
+    rules:
+      style:
+        todo-comment:
+          level: ignore
+`
+	policy := "package p\n\nimport rego.v1\n\n# TODO: fix\nallow := true\n"
+
+	files := map[string]string{
+		".git/HEAD":              "",
+		".regal/config.yaml":     rootConfig,
+		"sub/.regal/config.yaml": "rules:\n  style:\n    todo-comment:\n      level: warning\n",
+		"p.rego":                 policy,
+		"gen/p.rego":             policy,
+		"sub/p.rego":             policy,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(td, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)("lint", "--format", "json", td)
+
+	expectExitCode(t, err, 3, &stdout, &stderr)
+
+	var rep report.Report
+
+	if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatalf("expected JSON response, got %v", stdout.String())
+	}
+
+	levels := make(map[string]string)
+
+	for _, violation := range rep.Violations {
+		if violation.Title == "todo-comment" {
+			rel, _ := filepath.Rel(td, violation.Location.File)
+			levels[filepath.ToSlash(rel)] = violation.Level
+		}
+	}
+
+	if exp := map[string]string{"p.rego": "error", "sub/p.rego": "warning"}; !maps.Equal(levels, exp) {
+		t.Errorf("expected todo-comment violations %v, got %v", exp, levels)
+	}
+}
+
+func TestLintUnknownRulesInConfig(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD": "",
+		".regal/config.yaml": `rules:
+  naming:
+    acme-corp-package:
+      level: error
+`,
+		".regal/rules/custom.rego": `# METADATA
+# description: custom rule
+package custom.regal.rules.naming["acme-corp-package"]
+
+import rego.v1
+
+report contains violation if {
+	false
+	violation := {}
+}
+`,
+		"sub/.regal/config.yaml": "rules:\n  styel:\n    line-length:\n      level: ignore\n",
+		"p.rego":                 "package p\n\nimport rego.v1\n\nallow := true\n",
+		"sub/p.rego":             "package p\n\nimport rego.v1\n\nallow := true\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(td, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	// custom rules in the .regal directory are known
+	err := regal(&stdout, &stderr)("lint", filepath.Join(td, "p.rego"))
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	if strings.Contains(stderr.String(), "warning") {
+		t.Errorf("expected no warnings for custom rules configured, got:\n%s", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// unknown categories and rules are warned about, but don't stop linting
+	err = regal(&stdout, &stderr)("lint", filepath.Join(td, "p.rego"), filepath.Join(td, "sub"))
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	expected := "warning: " + filepath.Join(td, "sub", ".regal", "config.yaml") +
+		":2:3: unknown category styel, did you mean style?"
+	if !strings.Contains(stderr.String(), expected) {
+		t.Errorf("expected stderr to contain %q, got:\n%s", expected, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// other errors in config files still do
+	invalid := "rules:\n  style:\n    line-length:\n      level: critical\n"
+	if err := os.WriteFile(filepath.Join(td, "sub", ".regal", "config.yaml"), []byte(invalid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err = regal(&stdout, &stderr)("lint", filepath.Join(td, "sub"))
+
+	expectExitCode(t, err, 1, &stdout, &stderr)
+}
+
+func TestConfigShow(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD":          "",
+		".regal/config.yaml": "rules:\n  style:\n    line-length:\n      level: warning\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(td, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)("config", "show", "--format", "json", "--disable", "opa-fmt",
+		filepath.Join(td, "p.rego"))
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	var explanation config.Explanation
+
+	if err = json.Unmarshal(stdout.Bytes(), &explanation); err != nil {
+		t.Fatalf("expected JSON response, got %v", stdout.String())
+	}
+
+	expected := map[string]config.Setting{
+		"line-length":  {Value: "warning", Source: filepath.Join(td, ".regal", "config.yaml")},
+		"opa-fmt":      {Value: "ignore", Source: "--disable"},
+		"todo-comment": {Value: "error", Source: "provided"},
+	}
+
+	for title, exp := range expected {
+		if got := explanation.Rules["style"][title]["level"]; got != exp {
+			t.Errorf("%s: expected level %v, got %v", title, exp, got)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD": "",
+		".regal/config.yaml": `rules:
+  style:
+    line-length:
+      level: critical
+  naming:
+    acme-corp-package:
+      level: error
+  namng:
+    rule: {}
+`,
+		".regal/rules/custom.rego": `# METADATA
+# description: custom rule
+package custom.regal.rules.naming["acme-corp-package"]
+
+import rego.v1
+
+report contains violation if {
+	false
+	violation := {}
+}
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(td, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regal(&stdout, &stderr)("config", "validate", "--format", "json", td)
+
+	expectExitCode(t, err, 1, &stdout, &stderr)
+
+	var errs config.ValidationErrors
+
+	if err = json.Unmarshal(stdout.Bytes(), &errs); err != nil {
+		t.Fatalf("expected JSON response, got %v (stderr: %s)", stdout.String(), stderr.String())
+	}
+
+	configFile := filepath.Join(td, ".regal", "config.yaml")
+
+	expected := config.ValidationErrors{
+		{File: configFile, Line: 4, Column: 14, Message: `unknown level "critical", must be one of ` +
+			"error, warning, info, hint or ignore"},
+		{
+			File: configFile, Line: 8, Column: 3, Message: "unknown category namng, did you mean naming?",
+			UnknownRule: true,
+		},
+	}
+
+	if !slices.Equal(errs, expected) {
+		t.Errorf("expected errors %v, got %v", expected, errs)
+	}
+}
+
+func TestLintStdin(t *testing.T) {
+	t.Parallel()
+
+	td := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(td, ".regal"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := "ignore:\n  files:\n    - ignored/\nrules:\n  style:\n    prefer-snake-case:\n      level: ignore\n"
+	if err := os.WriteFile(filepath.Join(td, ".regal", "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := "package p\n\nimport rego.v1\n\ncamelCase := true\n\nuserName := input.x\n"
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	// the virtual path does not exist, but the config should still be found from its closest existing parent
+	virtualPath := filepath.Join(td, "policy", "authz.rego")
+
+	err := regalWithStdin(strings.NewReader(policy), &stdout, &stderr)(
+		"lint", "--format", "json", "--stdin-filename", virtualPath, "-",
+	)
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	var rep report.Report
+
+	if err = json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatalf("expected JSON response, got %v", stdout.String())
+	}
+
+	// prefer-snake-case is ignored in config, so no violations are expected
+	if rep.Summary.FilesScanned != 1 || rep.Summary.NumViolations != 0 {
+		t.Errorf("expected 1 file scanned with no violations, got %v", rep.Violations)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// policy provided for an ignored file isn't even parsed
+	err = regalWithStdin(strings.NewReader("package"), &stdout, &stderr)(
+		"lint", "--stdin-filename", filepath.Join(td, "ignored", "p.rego"), "-",
+	)
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+}
+
+func TestFixStdin(t *testing.T) {
+	t.Parallel()
+
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+
+	err := regalWithStdin(strings.NewReader("package p\nallow { input.x == 1 }\n"), &stdout, &stderr)(
+		"fix", "--stdin-filename", "p.rego", "-",
+	)
+
+	expectExitCode(t, err, 0, &stdout, &stderr)
+
+	if exp := "package p\n\nimport rego.v1\n\nallow if input.x == 1\n"; stdout.String() != exp {
+		t.Errorf("expected fixed policy on stdout:\n%s\ngot:\n%s", exp, stdout.String())
+	}
+
+	if !strings.Contains(stderr.String(), "1 fix applied") {
+		t.Errorf("expected fix report on stderr, got %q", stderr.String())
+	}
+}
+
 func TestTestRegalBundledBundle(t *testing.T) {
 	t.Parallel()
 
@@ -875,6 +1571,23 @@ func regal(outs ...io.Writer) func(...string) error {
 	}
 }
 
+func regalWithStdin(stdin io.Reader, outs ...io.Writer) func(...string) error {
+	return func(args ...string) error {
+		c := exec.Command(binary(), args...)
+		c.Stdin = stdin
+
+		if len(outs) > 0 {
+			c.Stdout = outs[0]
+		}
+
+		if len(outs) > 1 {
+			c.Stderr = outs[1]
+		}
+
+		return c.Run() //nolint:wrapcheck // We're in tests. This is fine.
+	}
+}
+
 type exitStatus interface {
 	ExitStatus() int
 }
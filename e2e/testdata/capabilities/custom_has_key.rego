@@ -1,6 +1,6 @@
 package custom_has_key
 
-import rego.v1
+import future.keywords.if
 
 has_key(map, key) if {
 	_ = map[key]
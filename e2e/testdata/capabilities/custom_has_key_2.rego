@@ -1,6 +1,6 @@
 package custom_has_key_2
 
-import rego.v1
+import future.keywords.if
 
 # This is here to make sure we deal with multiple notices correctly,
 # and don't report duplicates multiple times.
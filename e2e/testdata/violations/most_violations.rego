@@ -269,6 +269,10 @@ fine if not not_fine
 # rule name repeats package name
 all_violations := true
 
+# unused ignore directive
+# regal ignore:line-length
+no_long_lines := true
+
 ### Performance
 
 with_outside_test if {
@@ -0,0 +1,206 @@
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Regal Lint Report</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+  h1 { margin-bottom: 0.25rem; }
+  h2 { margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.25rem; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+  th.sortable { cursor: pointer; user-select: none; }
+  th.sortable::after { content: " \2195"; color: #8c959f; }
+  td.num, th.num { text-align: right; }
+  pre { margin: 0.4rem 0 0 0; padding: 0.4rem; background: #f6f8fa; border-radius: 4px; overflow-x: auto; }
+  .gutter { color: #8c959f; user-select: none; padding-right: 1rem; }
+  .highlight { background: #fff8c5; }
+  .highlight .gutter { color: #9a6700; font-weight: bold; }
+  .level { font-weight: 600; }
+  .level-error { color: #cf222e; }
+  .level-warning { color: #9a6700; }
+  .level-info { color: #0969da; }
+  .level-hint { color: #57606a; }
+  .stats span { margin-right: 1.5rem; }
+  .filters { margin: 1rem 0; display: flex; gap: 1rem; }
+  .resources { margin: 0.4rem 0 0 0; padding-left: 1.2rem; }
+  .empty { color: #57606a; }
+</style>
+</head>
+<body>
+<h1>Regal Lint Report</h1>
+<p class="stats">
+  <span><strong>{{ .Report.Summary.FilesScanned }}</strong> files linted</span>
+  <span><strong>{{ .Report.Summary.NumViolations }}</strong> violations</span>
+  <span><strong>{{ .Report.Summary.FilesFailed }}</strong> files with violations</span>
+  <span><strong>{{ .Report.Summary.RulesSkipped }}</strong> rules skipped</span>
+</p>
+
+<h2>Summary</h2>
+{{- if .Categories }}
+<table id="summary">
+  <thead>
+    <tr>
+      <th>Category</th>
+      {{- range .Levels }}
+      <th class="num">{{ . }}</th>
+      {{- end }}
+      <th class="num">total</th>
+    </tr>
+  </thead>
+  <tbody>
+    {{- range .Categories }}
+    <tr>
+      <td>{{ .Category }}</td>
+      {{- $counts := .Counts }}
+      {{- range $.Levels }}
+      <td class="num">{{ index $counts . }}</td>
+      {{- end }}
+      <td class="num">{{ .Total }}</td>
+    </tr>
+    {{- end }}
+  </tbody>
+</table>
+{{- else }}
+<p class="empty">No violations found.</p>
+{{- end }}
+
+{{- if .Violations }}
+<h2>Violations</h2>
+<div class="filters">
+  <input id="filter-text" type="search" placeholder="Filter violations" aria-label="Filter violations">
+  <select id="filter-level" aria-label="Filter by level">
+    <option value="">All levels</option>
+    {{- range .Levels }}
+    <option value="{{ . }}">{{ . }}</option>
+    {{- end }}
+  </select>
+  <select id="filter-category" aria-label="Filter by category">
+    <option value="">All categories</option>
+    {{- range .Categories }}
+    <option value="{{ .Category }}">{{ .Category }}</option>
+    {{- end }}
+  </select>
+</div>
+<table id="violations">
+  <thead>
+    <tr>
+      <th class="sortable" data-column="0">Level</th>
+      <th class="sortable" data-column="1">Category</th>
+      <th class="sortable" data-column="2">Rule</th>
+      <th class="sortable" data-column="3">Location</th>
+      <th>Description</th>
+    </tr>
+  </thead>
+  <tbody>
+    {{- range .Violations }}
+    <tr data-level="{{ .Level }}" data-category="{{ .Category }}" data-sort-location="{{ .SortKey }}">
+      <td class="level level-{{ .Level }}">{{ .Level }}</td>
+      <td>{{ .Category }}</td>
+      <td>{{ .Title }}</td>
+      <td>{{ .Location }}</td>
+      <td>
+        {{ .Description }}
+        {{- if .Snippet }}
+        <pre>{{ range .Snippet }}<span{{ if .Highlight }} class="highlight"{{ end }}><span class="gutter">{{ .Number }}</span>{{ .Text }}</span>
+{{ end }}</pre>
+        {{- end }}
+        {{- if .RelatedResources }}
+        <ul class="resources">
+          {{- range .RelatedResources }}
+          <li><a href="{{ .Reference }}">{{ .Description }}</a></li>
+          {{- end }}
+        </ul>
+        {{- end }}
+      </td>
+    </tr>
+    {{- end }}
+  </tbody>
+</table>
+{{- end }}
+
+{{- if .Notices }}
+<h2>Notices</h2>
+<ul>
+  {{- range .Notices }}
+  <li><strong>{{ .Category }}/{{ .Title }}</strong>: {{ .Description }}</li>
+  {{- end }}
+</ul>
+{{- end }}
+
+{{- if .Profile }}
+<h2>Profile</h2>
+<table id="profile">
+  <thead>
+    <tr>
+      <th class="num">Time</th>
+      <th class="num">Num Eval</th>
+      <th class="num">Num Redo</th>
+      <th class="num">Num Gen Expr</th>
+      <th>Location</th>
+    </tr>
+  </thead>
+  <tbody>
+    {{- range .Profile }}
+    <tr>
+      <td class="num">{{ .Time }}</td>
+      <td class="num">{{ .NumEval }}</td>
+      <td class="num">{{ .NumRedo }}</td>
+      <td class="num">{{ .NumGenExpr }}</td>
+      <td>{{ .Location }}</td>
+    </tr>
+    {{- end }}
+  </tbody>
+</table>
+{{- end }}
+
+<script>
+(function () {
+  var table = document.getElementById("violations");
+  if (!table) {
+    return;
+  }
+
+  var body = table.tBodies[0];
+  var text = document.getElementById("filter-text");
+  var level = document.getElementById("filter-level");
+  var category = document.getElementById("filter-category");
+
+  function filter() {
+    var needle = text.value.toLowerCase();
+
+    Array.prototype.forEach.call(body.rows, function (row) {
+      var visible = (!level.value || row.dataset.level === level.value) &&
+        (!category.value || row.dataset.category === category.value) &&
+        (!needle || row.textContent.toLowerCase().indexOf(needle) !== -1);
+
+      row.style.display = visible ? "" : "none";
+    });
+  }
+
+  [text, level, category].forEach(function (el) {
+    el.addEventListener("input", filter);
+  });
+
+  var direction = {};
+
+  Array.prototype.forEach.call(table.querySelectorAll("th.sortable"), function (th) {
+    th.addEventListener("click", function () {
+      var column = parseInt(th.dataset.column, 10);
+      var order = direction[column] = -(direction[column] || -1);
+
+      var key = function (row) {
+        // sort locations by file and then numerically by line and column
+        return column === 3 ? row.dataset.sortLocation : row.cells[column].textContent;
+      };
+
+      Array.prototype.slice.call(body.rows)
+        .sort(function (a, b) { return key(a).localeCompare(key(b)) * order; })
+        .forEach(function (row) { body.appendChild(row); });
+    });
+  });
+})();
+</script>
+</body>
+</html>
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive range of lines in a file.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes contains the lines changed in each file, keyed by the absolute path of the file.
+type Changes struct {
+	files map[string][]LineRange
+}
+
+// wholeFile is used for files that are entirely new, like untracked files.
+var wholeFile = LineRange{Start: 1, End: math.MaxInt} //nolint:gochecknoglobals
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`) //nolint:gochecknoglobals
+
+// ChangedSince returns the changes made to Rego files in the git repository containing dir, compared
+// to ref. Changes are determined from the merge base of ref and HEAD, so that changes made to ref after
+// the current branch was created are not included. Uncommitted and untracked files are included.
+func ChangedSince(ctx context.Context, dir, ref string) (*Changes, error) {
+	root, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository root: %w", err)
+	}
+
+	root = strings.TrimSpace(root)
+
+	base, err := run(ctx, root, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base of %s and HEAD: %w", ref, err)
+	}
+
+	// paths are printed without prefix, regardless of diff.noprefix or diff.mnemonicPrefix being configured
+	diff, err := run(ctx, root,
+		"diff", "--unified=0", "--no-color", "--no-ext-diff", "--no-renames", "--no-prefix", strings.TrimSpace(base),
+		"--", "*.rego",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", ref, err)
+	}
+
+	changes, err := parseDiff(root, strings.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := run(ctx, root, "ls-files", "-z", "--others", "--exclude-standard", "--", "*.rego")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	for _, file := range strings.Split(untracked, "\x00") {
+		if file != "" {
+			changes.files[normalize(filepath.Join(root, file))] = []LineRange{wholeFile}
+		}
+	}
+
+	return changes, nil
+}
+
+// Files returns the absolute paths of all changed files.
+func (c *Changes) Files() []string {
+	files := make([]string, 0, len(c.files))
+	for file := range c.files {
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// Within returns the changed files found at any of paths, which may be files or directories. Files are returned
+// relative to the path they were found at, in the same form as the path, and sorted.
+func (c *Changes) Within(paths []string) []string {
+	within := make([]string, 0)
+
+	for _, path := range paths {
+		root := normalize(path)
+
+		for file := range c.files {
+			rel, err := filepath.Rel(root, file)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+
+			if name := filepath.Join(path, rel); !slices.Contains(within, name) {
+				within = append(within, name)
+			}
+		}
+	}
+
+	slices.Sort(within)
+
+	return within
+}
+
+// HasFile returns true if the file at path has been changed.
+func (c *Changes) HasFile(path string) bool {
+	_, ok := c.files[normalize(path)]
+
+	return ok
+}
+
+// HasLines returns true if any of the lines between start and end (inclusive) in the file at path
+// have been changed.
+func (c *Changes) HasLines(path string, start, end int) bool {
+	for _, r := range c.files[normalize(path)] {
+		if start <= r.End && end >= r.Start {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseDiff parses the output of git diff --unified=0 --no-prefix, where file paths are relative to root.
+func parseDiff(root string, r io.Reader) (*Changes, error) {
+	changes := &Changes{files: make(map[string][]LineRange)}
+
+	var current string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = ""
+
+			name, err := diffPath(strings.TrimPrefix(line, "+++ "))
+			if err != nil {
+				return nil, err
+			}
+
+			if name != "/dev/null" {
+				current = normalize(filepath.Join(root, name))
+				// a file may be changed only by deleting lines, so add it even if no ranges follow
+				if _, ok := changes.files[current]; !ok {
+					changes.files[current] = nil
+				}
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("failed to parse hunk header %q", line)
+			}
+
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+
+			if count == 0 {
+				// only deletions, which we consider to be a change of the line following them
+				count = 1
+				start++
+			}
+
+			changes.files[current] = append(changes.files[current], LineRange{Start: start, End: start + count - 1})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	return changes, nil
+}
+
+// diffPath returns the path of a file header in a diff, which git quotes, using C-style escapes, when the path
+// contains characters like quotes or control characters, and terminates with a tab when the path contains spaces.
+func diffPath(name string) (string, error) {
+	if !strings.HasPrefix(name, `"`) {
+		return strings.TrimSuffix(name, "\t"), nil
+	}
+
+	unquoted, err := strconv.Unquote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse file path %s: %w", name, err)
+	}
+
+	return unquoted, nil
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	// paths with non-ASCII characters are printed as is, rather than quoted
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "core.quotePath=false"}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+
+		return "", fmt.Errorf("failed to run git: %w", err)
+	}
+
+	return stdout.String(), nil
+}
+
+// normalize returns the absolute path with any symlinks resolved, so that paths given to the linter
+// can be compared to those reported by git.
+func normalize(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+
+	return abs
+}
@@ -0,0 +1,175 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/styrainc/regal/internal/testutil"
+)
+
+func TestParseDiff(t *testing.T) {
+	t.Parallel()
+
+	diff := `diff --git p.rego p.rego
+index 1111111..2222222 100644
+--- p.rego
++++ p.rego
+@@ -3 +3 @@ import rego.v1
+-allow := false
++allow := true
+@@ -10,0 +11,3 @@ deny if {
++	input.x
++	input.y
++	input.z
+@@ -20,2 +23,0 @@ deny if {
+-	input.a
+-	input.b
+diff --git old.rego old.rego
+deleted file mode 100644
+--- old.rego
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package old
+`
+
+	changes := testutil.Must(parseDiff("/repo", strings.NewReader(diff)))(t)
+
+	if len(changes.Files()) != 1 {
+		t.Fatalf("expected 1 changed file, got %v", changes.Files())
+	}
+
+	cases := []struct {
+		start, end int
+		exp        bool
+	}{
+		{3, 3, true},
+		{4, 4, false},
+		{1, 10, true},
+		{12, 12, true},
+		{14, 14, false},
+		{24, 24, true},
+		{25, 30, false},
+	}
+
+	for _, tc := range cases {
+		if act := changes.HasLines("/repo/p.rego", tc.start, tc.end); act != tc.exp {
+			t.Errorf("expected HasLines(%d, %d) to be %v, got %v", tc.start, tc.end, tc.exp, act)
+		}
+	}
+
+	if changes.HasFile("/repo/old.rego") {
+		t.Error("expected deleted file not to be reported as changed")
+	}
+}
+
+func TestParseDiffPaths(t *testing.T) {
+	t.Parallel()
+
+	diff := "diff --git \"p\\303\\245.rego\" \"p\\303\\245.rego\"\n" +
+		"--- \"p\\303\\245.rego\"\n" +
+		"+++ \"p\\303\\245.rego\"\n" +
+		"@@ -1 +1 @@\n" +
+		"diff --git b/p.rego b/p.rego\n" +
+		"--- b/p.rego\n" +
+		"+++ b/p.rego\n" +
+		"@@ -2 +2 @@\n" +
+		"diff --git with space.rego with space.rego\n" +
+		"--- with space.rego\t\n" +
+		"+++ with space.rego\t\n" +
+		"@@ -3 +3 @@\n"
+
+	changes := testutil.Must(parseDiff("/repo", strings.NewReader(diff)))(t)
+
+	// quoted paths are unquoted, and paths are not expected to have a prefix
+	for path, line := range map[string]int{"/repo/på.rego": 1, "/repo/b/p.rego": 2, "/repo/with space.rego": 3} {
+		if !changes.HasLines(path, line, line) {
+			t.Errorf("expected line %d of %s to be changed, got changes in %v", line, path, changes.Files())
+		}
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	td := t.TempDir()
+
+	git := func(args ...string) {
+		t.Helper()
+
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = td
+
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+
+	write := func(name, content string) {
+		t.Helper()
+
+		if err := os.WriteFile(filepath.Join(td, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "--quiet")
+	// prefixes configured are ignored
+	git("config", "diff.mnemonicPrefix", "true")
+	write("a.rego", "package a\n\nx := 1\n\ny := 2\n")
+	write("på.rego", "package p\n")
+	write("b.rego", "package b\n")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+	git("branch", "base")
+
+	write("a.rego", "package a\n\nx := 1\n\ny := 3\n")
+	write("c.rego", "package c\n")
+	write("på.rego", "package p\n\nx := 1\n")
+	write("new file.rego", "package n\n")
+	write("notes.txt", "not rego")
+
+	changes := testutil.Must(ChangedSince(context.Background(), td, "base"))(t)
+
+	if len(changes.Files()) != 4 {
+		t.Errorf("expected 2 changed files, got %v", changes.Files())
+	}
+
+	if !changes.HasLines(filepath.Join(td, "a.rego"), 5, 5) || changes.HasLines(filepath.Join(td, "a.rego"), 3, 3) {
+		t.Error("expected only line 5 of a.rego to be changed")
+	}
+
+	if changes.HasFile(filepath.Join(td, "b.rego")) {
+		t.Error("expected b.rego not to be changed")
+	}
+
+	if !changes.HasLines(filepath.Join(td, "c.rego"), 1, 1) {
+		t.Error("expected untracked c.rego to be changed")
+	}
+
+	if !changes.HasLines(filepath.Join(td, "på.rego"), 3, 3) {
+		t.Error("expected line 3 of på.rego to be changed")
+	}
+
+	if !changes.HasFile(filepath.Join(td, "new file.rego")) {
+		t.Error("expected untracked new file.rego to be changed")
+	}
+
+	within := changes.Within([]string{td, filepath.Join(td, "c.rego"), filepath.Join(td, "sub")})
+	expected := []string{
+		filepath.Join(td, "a.rego"), filepath.Join(td, "c.rego"),
+		filepath.Join(td, "new file.rego"), filepath.Join(td, "på.rego"),
+	}
+
+	if !slices.Equal(within, expected) {
+		t.Errorf("expected changed files within paths to be %v, got %v", expected, within)
+	}
+}
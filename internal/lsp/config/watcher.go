@@ -2,12 +2,21 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// skipDirs are directories never watched by WatchAll, as they commonly contain many files and change often.
+var skipDirs = []string{".git", ".idea", "node_modules"} //nolint:gochecknoglobals
+
 type Watcher struct {
 	Reload chan string
 	Drop   chan struct{}
@@ -15,6 +24,11 @@ type Watcher struct {
 	path        string
 	pathUpdates chan string
 
+	// files and directories watched by WatchAll, where directories are watched recursively
+	mu    sync.Mutex
+	files map[string]struct{}
+	dirs  map[string]struct{}
+
 	fsWatcher *fsnotify.Watcher
 
 	errorWriter io.Writer
@@ -30,10 +44,14 @@ func NewWatcher(opts *WatcherOpts) *Watcher {
 		Reload:      make(chan string, 1),
 		Drop:        make(chan struct{}, 1),
 		pathUpdates: make(chan string, 1),
+		errorWriter: io.Discard,
 	}
 
 	if opts != nil {
-		w.errorWriter = opts.ErrorWriter
+		if opts.ErrorWriter != nil {
+			w.errorWriter = opts.ErrorWriter
+		}
+
 		w.path = opts.Path
 	}
 
@@ -51,6 +69,11 @@ func (w *Watcher) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
 	}
 
+	w.mu.Lock()
+	w.files = make(map[string]struct{})
+	w.dirs = make(map[string]struct{})
+	w.mu.Unlock()
+
 	go func() {
 		w.loop(ctx)
 	}()
@@ -85,13 +108,21 @@ func (w *Watcher) loop(ctx context.Context) {
 				return
 			}
 
-			if event.Has(fsnotify.Write) {
-				w.Reload <- event.Name
+			if w.path != "" && event.Name == w.path {
+				if event.Has(fsnotify.Write) {
+					w.Reload <- event.Name
+				}
+
+				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					w.path = ""
+					w.Drop <- struct{}{}
+				}
+
+				continue
 			}
 
-			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
-				w.path = ""
-				w.Drop <- struct{}{}
+			if !w.handleWatchAllEvent(ctx, event) {
+				return
 			}
 		case err := <-w.fsWatcher.Errors:
 			fmt.Fprintf(w.errorWriter, "config watcher error: %v\n", err)
@@ -106,10 +137,143 @@ func (w *Watcher) loop(ctx context.Context) {
 	}
 }
 
+// handleWatchAllEvent sends the path of a file watched by WatchAll on Reload when it is created, written, removed
+// or renamed, and starts watching directories created in directories watched. It returns false if ctx is done.
+func (w *Watcher) handleWatchAllEvent(ctx context.Context, event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return true
+	}
+
+	w.mu.Lock()
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() && w.inWatchedDir(event.Name) {
+			if err := w.addDir(event.Name); err != nil {
+				fmt.Fprintf(w.errorWriter, "failed to watch new directory: %v\n", err)
+			}
+		}
+	}
+
+	watched := w.isWatched(event.Name)
+
+	w.mu.Unlock()
+
+	if !watched {
+		return true
+	}
+
+	select {
+	case w.Reload <- event.Name:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Watch watches the config file at configFilePath, replacing any config file watched before. Its path is sent on
+// Reload when written, and Drop is signalled when it's removed.
 func (w *Watcher) Watch(configFilePath string) {
 	w.pathUpdates <- configFilePath
 }
 
+// WatchAll watches paths, which may be files or directories, in addition to any watched before. The path of each
+// file created, written, removed or renamed is sent on Reload. Directories are watched recursively, including any
+// directories created in them later, and files that don't exist yet are watched for creation, as long as their
+// parent directory exists. The watcher must be started first.
+func (w *Watcher) WatchAll(paths ...string) error {
+	if w.fsWatcher == nil {
+		return errors.New("watcher not started")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range paths {
+		if err := w.watchAll(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) watchAll(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+	}
+
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		return w.addDir(abs)
+	}
+
+	// files are watched through their parent directory, as editors commonly replace
+	// files on save, which would otherwise end the watch
+	w.files[abs] = struct{}{}
+
+	if err := w.add(filepath.Dir(abs)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isWatched returns true if path is either a file watched by WatchAll, or found in a directory watched
+// recursively, as opposed to only watched for the sake of a file in it.
+func (w *Watcher) isWatched(path string) bool {
+	if _, ok := w.files[path]; ok {
+		return true
+	}
+
+	return w.inWatchedDir(path)
+}
+
+func (w *Watcher) inWatchedDir(path string) bool {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if _, ok := w.dirs[dir]; ok {
+			return true
+		}
+
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
+
+// addDir watches dir and all directories below it.
+func (w *Watcher) addDir(dir string) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != dir && slices.Contains(skipDirs, d.Name()) {
+			return filepath.SkipDir
+		}
+
+		w.dirs[path] = struct{}{}
+
+		return w.add(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+func (w *Watcher) add(path string) error {
+	if slices.Contains(w.fsWatcher.WatchList(), path) {
+		return nil
+	}
+
+	return w.fsWatcher.Add(path) //nolint:wrapcheck
+}
+
 func (w *Watcher) Stop() error {
 	if w.fsWatcher != nil {
 		err := w.fsWatcher.Close()
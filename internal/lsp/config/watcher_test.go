@@ -3,6 +3,8 @@ package config
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 )
@@ -69,3 +71,81 @@ foo: baz
 		t.Fatal("timeout waiting for config drop event")
 	}
 }
+
+func TestWatcherWatchAll(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	policyDir := filepath.Join(tempDir, "policy")
+	configFile := filepath.Join(tempDir, ".regal", "config.yaml")
+
+	for _, dir := range []string{policyDir, filepath.Dir(configFile)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher := NewWatcher(&WatcherOpts{ErrorWriter: os.Stderr})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := watcher.Start(ctx); err != nil {
+		t.Fatalf("failed to start watcher: %v", err)
+	}
+
+	if err := watcher.WatchAll(policyDir, configFile); err != nil {
+		t.Fatalf("failed to watch paths: %v", err)
+	}
+
+	// paths reloaded so far, as a single change may be reported by more than one event
+	var reloaded []string
+
+	// waits for a reload of each expected path, failing on any path not expected now or before
+	expectReloads := func(expected ...string) {
+		t.Helper()
+
+		for len(expected) > 0 {
+			select {
+			case path := <-watcher.Reload:
+				if !slices.Contains(expected, path) && !slices.Contains(reloaded, path) {
+					t.Fatalf("expected reload of %v, got %s", expected, path)
+				}
+
+				reloaded = append(reloaded, path)
+
+				expected = slices.DeleteFunc(expected, func(p string) bool { return p == path })
+			case <-time.After(time.Second):
+				t.Fatalf("timeout waiting for reload of %v", expected)
+			}
+		}
+	}
+
+	write := func(path, content string) {
+		t.Helper()
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the config file does not exist yet, but its creation should be seen
+	write(configFile, "rules: {}\n")
+	expectReloads(configFile)
+
+	// files in directories created after the watch was started are watched too
+	nestedDir := filepath.Join(policyDir, "nested")
+	if err := os.Mkdir(nestedDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	expectReloads(nestedDir)
+
+	write(filepath.Join(nestedDir, "q.rego"), "package q\n")
+	expectReloads(filepath.Join(nestedDir, "q.rego"))
+
+	// files next to a watched file are not reported
+	write(filepath.Join(tempDir, ".regal", "other.yaml"), "foo: bar\n")
+	write(configFile, "rules:\n  style: {}\n")
+	expectReloads(configFile)
+}
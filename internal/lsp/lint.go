@@ -140,10 +140,47 @@ func updateParse(ctx context.Context, cache *cache.Cache, store storage.Store, f
 	return false, nil
 }
 
+// configDiagnostics returns the errors found validating a config file as diagnostics, each highlighting the rest
+// of the line from where the error is located in the file, given the content of the file.
+func configDiagnostics(errs config.ValidationErrors, content string) []types.Diagnostic {
+	lines := strings.Split(content, "\n")
+	diags := make([]types.Diagnostic, 0, len(errs))
+
+	for _, err := range errs {
+		line := max(err.Line-1, 0)
+		character := max(err.Column-1, 0)
+
+		lineLength := character + 1
+		if line < len(lines) && len(lines[line]) > character {
+			lineLength = len(lines[line])
+		}
+
+		// unknown rules may be meant for another version of Regal, and are reported as warnings
+		severity := uint(1)
+		if err.UnknownRule {
+			severity = 2
+		}
+
+		diags = append(diags, types.Diagnostic{
+			Severity: severity,
+			Range: types.Range{
+				Start: types.Position{Line: uint(line), Character: uint(character)},
+				End:   types.Position{Line: uint(line), Character: uint(lineLength)},
+			},
+			Message: err.Message,
+			Source:  "regal/config",
+			Code:    "invalid-config",
+		})
+	}
+
+	return diags
+}
+
 func updateFileDiagnostics(
 	ctx context.Context,
 	cache *cache.Cache,
 	regalConfig *config.Config,
+	resolveConfig func(string) (*config.Config, error),
 	uri string,
 	rootDir string,
 ) error {
@@ -168,6 +205,10 @@ func updateFileDiagnostics(
 		regalInstance = regalInstance.WithUserConfig(*regalConfig)
 	}
 
+	if resolveConfig != nil {
+		regalInstance = regalInstance.WithConfigResolver(resolveConfig)
+	}
+
 	rpt, err := regalInstance.Lint(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to lint: %w", err)
@@ -176,15 +217,8 @@ func updateFileDiagnostics(
 	diags := make([]types.Diagnostic, 0)
 
 	for _, item := range rpt.Violations {
-		// here errors are presented as warnings, and warnings as info
-		// to differentiate from parse errors
-		severity := uint(2)
-		if item.Level == "warning" {
-			severity = 3
-		}
-
 		diags = append(diags, types.Diagnostic{
-			Severity: severity,
+			Severity: diagnosticSeverity(item.Level),
 			Range:    getRangeForViolation(item),
 			Message:  item.Description,
 			Source:   "regal/" + item.Category,
@@ -204,10 +238,25 @@ func updateFileDiagnostics(
 	return nil
 }
 
+// diagnosticSeverity returns the LSP DiagnosticSeverity for the level of a violation. Errors are presented
+// as warnings, and warnings as info, to differentiate them from parse errors. Info is presented as info too,
+// and hints as hints.
+func diagnosticSeverity(level string) uint {
+	switch level {
+	case config.LevelWarning, config.LevelInfo:
+		return 3
+	case config.LevelHint:
+		return 4
+	default:
+		return 2
+	}
+}
+
 func updateAllDiagnostics(
 	ctx context.Context,
 	cache *cache.Cache,
 	regalConfig *config.Config,
+	resolveConfig func(string) (*config.Config, error),
 	detachedURI string,
 ) error {
 	modules := cache.GetAllModules()
@@ -221,6 +270,10 @@ func updateAllDiagnostics(
 		regalInstance = regalInstance.WithUserConfig(*regalConfig)
 	}
 
+	if resolveConfig != nil {
+		regalInstance = regalInstance.WithConfigResolver(resolveConfig)
+	}
+
 	rpt, err := regalInstance.Lint(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to lint: %w", err)
@@ -230,15 +283,8 @@ func updateAllDiagnostics(
 	fileDiags := make(map[string][]types.Diagnostic)
 
 	for _, item := range rpt.Violations {
-		// here errors are presented as warnings, and warnings as info
-		// to differentiate from parse errors
-		severity := uint(2)
-		if item.Level == "warning" {
-			severity = 3
-		}
-
 		diag := types.Diagnostic{
-			Severity: severity,
+			Severity: diagnosticSeverity(item.Level),
 			Range:    getRangeForViolation(item),
 			Message:  item.Description,
 			Source:   "regal/" + item.Category,
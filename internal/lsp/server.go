@@ -15,7 +15,6 @@ import (
 	"time"
 
 	"github.com/sourcegraph/jsonrpc2"
-	"gopkg.in/yaml.v3"
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/format"
@@ -73,6 +72,7 @@ func NewLanguageServer(opts *LanguageServerOptions) *LanguageServer {
 		commandRequest:             make(chan types.ExecuteCommandParams, 10),
 		configWatcher:              lsconfig.NewWatcher(&lsconfig.WatcherOpts{ErrorWriter: opts.ErrorLog}),
 		completionsManager:         completions.NewDefaultManager(c, store),
+		configResolver:             config.NewResolver(),
 	}
 
 	return ls
@@ -87,6 +87,10 @@ type LanguageServer struct {
 	loadedConfig     *config.Config
 	loadedConfigLock sync.Mutex
 
+	// configResolver resolves the config of files configured differently by config files in .regal
+	// directories nearer to them than the workspace root, or by overrides
+	configResolver *config.Resolver
+
 	workspaceRootURI string
 	clientIdentifier clients.Identifier
 
@@ -104,6 +108,11 @@ type LanguageServer struct {
 	commandRequest             chan types.ExecuteCommandParams
 }
 
+// fileConfig returns the user config applying to the file with fileURI, or nil if no config file applies to it.
+func (l *LanguageServer) fileConfig(fileURI string) (*config.Config, error) {
+	return l.configResolver.ForFile(uri.ToPath(l.clientIdentifier, fileURI)) //nolint:wrapcheck
+}
+
 // fileUpdateEvent is sent to a channel when an update is required for a file.
 type fileUpdateEvent struct {
 	Reason  string
@@ -238,7 +247,7 @@ func (l *LanguageServer) StartDiagnosticsWorker(ctx context.Context) {
 			}
 
 			// otherwise, lint the file and send the diagnostics
-			err = updateFileDiagnostics(ctx, l.cache, l.loadedConfig, evt.URI, l.workspaceRootURI)
+			err = updateFileDiagnostics(ctx, l.cache, l.loadedConfig, l.fileConfig, evt.URI, l.workspaceRootURI)
 			if err != nil {
 				l.logError(fmt.Errorf("failed to update file diagnostics: %w", err))
 			}
@@ -256,7 +265,7 @@ func (l *LanguageServer) StartDiagnosticsWorker(ctx context.Context) {
 			}
 		case <-l.diagnosticRequestWorkspace:
 			// results will be sent in response to the next workspace/diagnostics request
-			err := updateAllDiagnostics(ctx, l.cache, l.loadedConfig, l.workspaceRootURI)
+			err := updateAllDiagnostics(ctx, l.cache, l.loadedConfig, l.fileConfig, l.workspaceRootURI)
 			if err != nil {
 				l.logError(fmt.Errorf("failed to update aggregate diagnostics (trigger): %w", err))
 			}
@@ -315,11 +324,14 @@ func (l *LanguageServer) StartConfigWorker(ctx context.Context) {
 
 			var userConfig config.Config
 
-			err = yaml.NewDecoder(configFile).Decode(&userConfig)
+			err = config.Decode(configFile, &userConfig)
+
+			l.sendConfigDiagnostics(ctx, path)
+
 			if err != nil && !errors.Is(err, io.EOF) {
 				l.logError(fmt.Errorf("failed to reload config: %w", err))
 
-				return
+				continue
 			}
 
 			mergedConfig, err := config.LoadConfigWithDefaultsFromBundle(&regalRules, &userConfig)
@@ -1978,6 +1990,46 @@ func (l *LanguageServer) sendFileDiagnostics(ctx context.Context, fileURI string
 	return nil
 }
 
+// sendConfigDiagnostics validates the config file at path against the built-in rules and any custom rules in the
+// .regal directory, and publishes the errors found as diagnostics of the config file, clearing any previously
+// published when none are found.
+func (l *LanguageServer) sendConfigDiagnostics(ctx context.Context, path string) {
+	regal := linter.NewLinter()
+
+	rulesDir := filepath.Join(filepath.Dir(path), "rules")
+	if info, err := os.Stat(rulesDir); err == nil && info.IsDir() {
+		regal = regal.WithCustomRules([]string{rulesDir})
+	}
+
+	known, err := regal.KnownRules(ctx)
+	if err != nil {
+		l.logError(fmt.Errorf("failed to load rules for validating config: %w", err))
+
+		return
+	}
+
+	errs, err := config.ValidateFile(path, known)
+	if err != nil {
+		errs = config.ValidationErrors{{File: path, Line: 1, Column: 1, Message: err.Error()}}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		l.logError(fmt.Errorf("failed to read config file: %w", err))
+
+		return
+	}
+
+	resp := types.FileDiagnostics{
+		Items: configDiagnostics(errs, string(content)),
+		URI:   uri.FromPath(l.clientIdentifier, path),
+	}
+
+	if err := l.conn.Notify(ctx, methodTextDocumentPublishDiagnostics, resp); err != nil {
+		l.logError(fmt.Errorf("failed to notify: %w", err))
+	}
+}
+
 func (l *LanguageServer) getFilteredModules() (map[string]*ast.Module, error) {
 	ignore := make([]string, 0)
 
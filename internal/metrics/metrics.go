@@ -16,6 +16,8 @@ const (
 	RegalLintGo               = "regal_lint_go"
 	RegalLintRego             = "regal_lint_rego"
 	RegalLintRegoAggregate    = "regal_lint_rego_aggregate"
+	RegalLintCacheHits        = "regal_lint_cache_hits"
+	RegalLintCacheMisses      = "regal_lint_cache_misses"
 )
 
 func FromExprStats(stats profiler.ExprStats) report.ProfileEntry {
@@ -0,0 +1,178 @@
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// Version is the version of the baseline file format.
+const Version = 1
+
+// Baseline is a set of violations recorded at some point in time, considered to be accepted.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry is a single violation in a baseline. Entries intentionally don't include the row or column of
+// the violation, but the (normalized) text of the line where it was found, which allows the line to
+// be moved without the violation being considered new.
+type Entry struct {
+	Rule string `json:"rule"`
+	File string `json:"file"`
+	Text string `json:"text"`
+}
+
+// String returns a short, human-readable, representation of the entry.
+func (e Entry) String() string {
+	if e.Text == "" {
+		return fmt.Sprintf("%s: %s", e.File, e.Rule)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Rule, e.Text)
+}
+
+// FromReport creates a new baseline from the violations in the report.
+func FromReport(r report.Report) Baseline {
+	entries := make([]Entry, 0, len(r.Violations))
+
+	for _, violation := range r.Violations {
+		entries = append(entries, EntryFor(violation))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+
+		if entries[i].Rule != entries[j].Rule {
+			return entries[i].Rule < entries[j].Rule
+		}
+
+		return entries[i].Text < entries[j].Text
+	})
+
+	return Baseline{Version: Version, Entries: entries}
+}
+
+// EntryFor returns the baseline entry, or fingerprint, for a violation.
+func EntryFor(violation report.Violation) Entry {
+	text := ""
+	if violation.Location.Text != nil {
+		text = normalize(*violation.Location.Text)
+	}
+
+	return Entry{
+		Rule: violation.Category + "/" + violation.Title,
+		File: filepath.ToSlash(violation.Location.File),
+		Text: text,
+	}
+}
+
+// Filter removes all violations found in the baseline from the report, and returns the filtered report
+// along with any entries in the baseline that no longer matched a violation, and which thus could be
+// pruned from the baseline. Each entry matches at most one violation, so if the same violation is found
+// on more lines than were recorded in the baseline, the additional occurrences are still reported.
+func (b Baseline) Filter(r report.Report) (report.Report, []Entry) {
+	remaining := make(map[Entry]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		remaining[entry]++
+	}
+
+	violations := make([]report.Violation, 0, len(r.Violations))
+
+	for _, violation := range r.Violations {
+		entry := EntryFor(violation)
+
+		if remaining[entry] > 0 {
+			remaining[entry]--
+
+			continue
+		}
+
+		violations = append(violations, violation)
+	}
+
+	stale := make([]Entry, 0)
+
+	for _, entry := range b.Entries {
+		if remaining[entry] > 0 {
+			remaining[entry]--
+
+			stale = append(stale, entry)
+		}
+	}
+
+	r.Violations = violations
+	r.Summary.NumViolations = len(violations)
+	r.Summary.FilesFailed = len(r.ViolationsFileCount())
+
+	return r, stale
+}
+
+// Read reads a baseline from r.
+func Read(r io.Reader) (Baseline, error) {
+	var b Baseline
+
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return Baseline{}, fmt.Errorf("failed to decode baseline: %w", err)
+	}
+
+	if b.Version != Version {
+		return Baseline{}, fmt.Errorf("unsupported baseline version %d, expected %d", b.Version, Version)
+	}
+
+	return b, nil
+}
+
+// ReadFile reads a baseline from the file at path.
+func ReadFile(path string) (Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Baseline{}, fmt.Errorf("failed to open baseline file: %w", err)
+	}
+
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Write writes the baseline to w.
+func (b Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+
+	return nil
+}
+
+// WriteFile writes the baseline to the file at path, replacing any existing file.
+func (b Baseline) WriteFile(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create baseline file: %w", err)
+	}
+
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	return b.Write(f)
+}
+
+// normalize trims the text and collapses any internal whitespace, so that changes in indentation or
+// formatting alone don't make a violation appear as new.
+func normalize(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,74 @@
+package baseline
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/styrainc/regal/internal/testutil"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+func violation(title, file string, row int, text string) report.Violation {
+	return report.Violation{
+		Title:    title,
+		Category: "style",
+		Level:    "error",
+		Location: report.Location{File: file, Row: row, Column: 1, Text: &text},
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	t.Parallel()
+
+	recorded := report.Report{Violations: []report.Violation{
+		violation("line-length", "p.rego", 3, "allow if input.very.long.reference"),
+		violation("prefer-snake-case", "p.rego", 5, "camelCase := true"),
+		violation("prefer-snake-case", "p.rego", 6, "camelCase := true"),
+		violation("todo-comment", "q.rego", 1, "# TODO: remove"),
+	}}
+
+	var buf bytes.Buffer
+
+	if err := FromReport(recorded).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	b := testutil.Must(Read(&buf))(t)
+
+	current := report.Report{Violations: []report.Violation{
+		// moved down and re-indented, should still match
+		violation("line-length", "p.rego", 10, "    allow   if input.very.long.reference"),
+		// three occurrences where two were recorded, one should be reported
+		violation("prefer-snake-case", "p.rego", 12, "camelCase := true"),
+		violation("prefer-snake-case", "p.rego", 13, "camelCase := true"),
+		violation("prefer-snake-case", "p.rego", 14, "camelCase := true"),
+		// new violation
+		violation("prefer-snake-case", "q.rego", 2, "fooBar := true"),
+	}}
+
+	filtered, stale := b.Filter(current)
+
+	if len(filtered.Violations) != 2 {
+		t.Fatalf("expected 2 new violations, got %d: %v", len(filtered.Violations), filtered.Violations)
+	}
+
+	if filtered.Violations[0].Location.Row != 14 || filtered.Violations[1].Location.File != "q.rego" {
+		t.Errorf("unexpected violations reported: %v", filtered.Violations)
+	}
+
+	if filtered.Summary.NumViolations != 2 || filtered.Summary.FilesFailed != 2 {
+		t.Errorf("expected summary to be updated, got %+v", filtered.Summary)
+	}
+
+	if len(stale) != 1 || stale[0].Rule != "style/todo-comment" {
+		t.Errorf("expected todo-comment entry to be stale, got %v", stale)
+	}
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Read(bytes.NewBufferString(`{"version": 99, "entries": []}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
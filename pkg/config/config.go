@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,9 +21,31 @@ const (
 	capabilitiesEngineOPA = "opa"
 	keyIgnore             = "ignore"
 	keyLevel              = "level"
+	keyMaxViolations      = "max-violations"
+	keyMaxWarnings        = "max-warnings"
+	keySchemas            = "schemas"
+	keyDirectory          = "directory"
 )
 
+// Levels that may be set for rules, either directly or through defaults. Violations are reported with the level of
+// the rule that reported them, where error is the most severe, followed by warning, info and hint. Rules with the
+// level ignore are not evaluated at all.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelInfo    = "info"
+	LevelHint    = "hint"
+	LevelIgnore  = "ignore"
+)
+
+// ReportedLevels are the levels violations may be reported with, ordered from most to least severe.
+var ReportedLevels = []string{LevelError, LevelWarning, LevelInfo, LevelHint} //nolint:gochecknoglobals
+
 type Config struct {
+	// Extends are the configs this config extends, as listed under extends. As the settings of those are merged
+	// into the config when loaded, this is informational only, and not (un)marshalled.
+	Extends []string `json:"-" yaml:"-"`
+
 	Rules        map[string]Category `json:"rules"                  yaml:"rules"`
 	Ignore       Ignore              `json:"ignore,omitempty"       yaml:"ignore,omitempty"`
 	Capabilities *Capabilities       `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
@@ -31,6 +55,8 @@ type Config struct {
 	Defaults Defaults `json:"-" yaml:"-"`
 
 	Features *Features `json:"features,omitempty" yaml:"features,omitempty"`
+
+	Schemas *Schemas `json:"schemas,omitempty" yaml:"schemas,omitempty"`
 }
 
 type Category map[string]Rule
@@ -43,9 +69,67 @@ type Defaults struct {
 }
 
 // Default represents global or category settings for rules,
-// currently only the level is supported.
+// currently the level and the number of warnings allowed.
 type Default struct {
-	Level string `json:"level" yaml:"level"`
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+	// MaxWarnings is the number of violations with the warning level allowed globally or in the category
+	// before failing, or nil if not set.
+	MaxWarnings *int `json:"max-warnings,omitempty" yaml:"max-warnings,omitempty"`
+}
+
+// Schemas configures the JSON schemas used to check references to input and data in policies.
+type Schemas struct {
+	// Directory is the path to a directory of schema files, where each file is identified by its path relative to
+	// the directory, without extension, e.g. schema.authz.request for authz/request.json. Relative paths are read
+	// relative to the directory holding the .regal directory, or that of the config file if not in one.
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
+	// Input maps schemas to be used for input in packages or entrypoints.
+	Input []SchemaMapping `json:"input,omitempty" yaml:"input,omitempty"`
+}
+
+// schemasBaseDir returns the directory that a relative schemas directory, set in a config file found in dir, is
+// relative to. That is the directory holding the .regal directory for config files in one, and dir itself otherwise.
+func schemasBaseDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	if filepath.Base(dir) == ".regal" {
+		return filepath.Dir(dir)
+	}
+
+	return dir
+}
+
+// withSchemasDirectory returns settings with a relative schemas directory made absolute by joining it with dir.
+// The settings provided are left unchanged, as they may be shared with other configs.
+func withSchemasDirectory(settings map[string]any, dir string) map[string]any {
+	schemas, ok := settings[keySchemas].(map[string]any)
+	if !ok {
+		return settings
+	}
+
+	directory, ok := schemas[keyDirectory].(string)
+	if !ok || directory == "" || filepath.IsAbs(directory) {
+		return settings
+	}
+
+	schemas = maps.Clone(schemas)
+	schemas[keyDirectory] = filepath.Join(dir, directory)
+
+	settings = maps.Clone(settings)
+	settings[keySchemas] = schemas
+
+	return settings
+}
+
+// SchemaMapping maps a schema to either a package or an entrypoint (i.e. a rule), given by their path without the
+// data prefix, e.g. policy.authz or policy.authz.allow.
+type SchemaMapping struct {
+	Package    string `json:"package,omitempty"    yaml:"package,omitempty"`
+	Entrypoint string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+	// Schema is the ID of a schema in the schema directory, with or without the schema prefix.
+	Schema string `json:"schema" yaml:"schema"`
 }
 
 type Features struct {
@@ -64,9 +148,22 @@ func (d *Default) mapToConfig(result any) error {
 
 	level, ok := resultMap[keyLevel].(string)
 	if ok {
+		if err := validateLevel(level); err != nil {
+			return err
+		}
+
 		d.Level = level
 	}
 
+	if raw, ok := resultMap[keyMaxWarnings]; ok {
+		maxWarnings, err := budgetValue(keyMaxWarnings, raw)
+		if err != nil {
+			return err
+		}
+
+		d.MaxWarnings = &maxWarnings
+	}
+
 	return nil
 }
 
@@ -79,7 +176,9 @@ type ExtraAttributes map[string]any
 type Rule struct {
 	Level  string
 	Ignore *Ignore `json:"ignore,omitempty" yaml:"ignore,omitempty"`
-	Extra  ExtraAttributes
+	// MaxViolations is the number of violations of the rule allowed before failing, or nil if not set.
+	MaxViolations *int
+	Extra         ExtraAttributes
 }
 
 type Capabilities struct {
@@ -186,7 +285,7 @@ func (config Config) MarshalYAML() (any, error) {
 	}
 
 	// place the global defaults at the top level under rules
-	if config.Defaults.Global.Level != "" {
+	if config.Defaults.Global.Level != "" || config.Defaults.Global.MaxWarnings != nil {
 		r, ok := unstructuredConfig["rules"].(map[string]any)
 		if !ok {
 			return nil, errors.New("rules in config were not a map")
@@ -224,6 +323,7 @@ type marshallingIntermediary struct {
 	// and configured elsewhere in the struct.
 	Rules        map[string]any `yaml:"rules"`
 	Ignore       Ignore         `yaml:"ignore"`
+	Schemas      *Schemas       `yaml:"schemas"`
 	Capabilities struct {
 		From struct {
 			Engine  string `yaml:"engine"`
@@ -247,6 +347,42 @@ type marshallingIntermediary struct {
 }
 
 func (config *Config) UnmarshalYAML(value *yaml.Node) error {
+	if errs := Validate(value, nil); len(errs) > 0 {
+		return errs
+	}
+
+	return config.unmarshal(value, "", nil)
+}
+
+// unmarshal unmarshals the config from value, first merging it on top of any configs it extends, where those
+// referenced by relative paths are read relative to dir, and validated against known, like Validate.
+func (config *Config) unmarshal(value *yaml.Node, dir string, known KnownRules) error {
+	var settings map[string]any
+
+	if err := value.Decode(&settings); err != nil {
+		return fmt.Errorf("unmarshalling config failed %w", err)
+	}
+
+	if extends, ok := settings[keyExtends]; ok {
+		resolved, err := resolveExtends(settings, dir, known)
+		if err != nil {
+			return fmt.Errorf("extending config failed: %w", err)
+		}
+
+		var node yaml.Node
+		if err := node.Encode(resolved); err != nil {
+			return fmt.Errorf("encoding extended config failed: %w", err)
+		}
+
+		if err := config.unmarshal(&node, dir, known); err != nil {
+			return err
+		}
+
+		config.Extends, _ = extendsRefs(extends)
+
+		return nil
+	}
+
 	var result marshallingIntermediary
 
 	if err := value.Decode(&result); err != nil {
@@ -266,6 +402,24 @@ func (config *Config) UnmarshalYAML(value *yaml.Node) error {
 
 	config.Ignore = result.Ignore
 
+	if result.Schemas != nil {
+		for i, mapping := range result.Schemas.Input {
+			if (mapping.Package == "") == (mapping.Entrypoint == "") {
+				return fmt.Errorf("schemas.input[%d]: exactly one of package or entrypoint must be provided", i)
+			}
+
+			if mapping.Schema == "" {
+				return fmt.Errorf("schemas.input[%d]: schema must be provided", i)
+			}
+		}
+
+		config.Schemas = result.Schemas
+
+		if dir != "" && config.Schemas.Directory != "" && !filepath.IsAbs(config.Schemas.Directory) {
+			config.Schemas.Directory = filepath.Join(schemasBaseDir(dir), config.Schemas.Directory)
+		}
+	}
+
 	capabilitiesFile := result.Capabilities.From.File
 	capabilitiesEngine := result.Capabilities.From.Engine
 	capabilitiesEngineVersion := result.Capabilities.From.Version
@@ -489,8 +643,12 @@ func (rule Rule) MarshalYAML() (interface{}, error) {
 		result[keyIgnore] = rule.Ignore
 	}
 
+	if rule.MaxViolations != nil {
+		result[keyMaxViolations] = *rule.MaxViolations
+	}
+
 	for key, val := range rule.Extra {
-		if key != keyIgnore && key != keyLevel {
+		if key != keyIgnore && key != keyLevel && key != keyMaxViolations {
 			result[key] = val
 		}
 	}
@@ -518,6 +676,10 @@ func (rule *Rule) mapToConfig(result any) error {
 
 	level, ok := ruleMap[keyLevel].(string)
 	if ok {
+		if err := validateLevel(level); err != nil {
+			return err
+		}
+
 		rule.Level = level
 	}
 
@@ -532,10 +694,70 @@ func (rule *Rule) mapToConfig(result any) error {
 		rule.Ignore = &dst
 	}
 
+	if raw, ok := ruleMap[keyMaxViolations]; ok {
+		maxViolations, err := budgetValue(keyMaxViolations, raw)
+		if err != nil {
+			return err
+		}
+
+		rule.MaxViolations = &maxViolations
+	}
+
 	rule.Extra = ruleMap
 
 	delete(rule.Extra, keyLevel)
 	delete(rule.Extra, keyIgnore)
+	delete(rule.Extra, keyMaxViolations)
 
 	return nil
 }
+
+// IsMoreSevereOrEqual returns true if level is at least as severe as other, where both are one of ReportedLevels.
+// Unknown levels are considered less severe than any of the known ones.
+func IsMoreSevereOrEqual(level, other string) bool {
+	return levelRank(level) >= levelRank(other)
+}
+
+func levelRank(level string) int {
+	for i, l := range ReportedLevels {
+		if l == level {
+			return len(ReportedLevels) - i
+		}
+	}
+
+	return 0
+}
+
+// budgetValue returns the value of a max-violations or max-warnings setting, which must be a non-negative integer.
+// Numbers are decoded as int from YAML, but as float64 from JSON.
+func budgetValue(key string, raw any) (int, error) {
+	var value int
+
+	switch v := raw.(type) {
+	case int:
+		value = v
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%s must be an integer, got %v", key, v)
+		}
+
+		value = int(v)
+	default:
+		return 0, fmt.Errorf("%s must be an integer, got %v", key, raw)
+	}
+
+	if value < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got %d", key, value)
+	}
+
+	return value, nil
+}
+
+func validateLevel(level string) error {
+	if level == LevelIgnore || slices.Contains(ReportedLevels, level) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown level %q, must be one of %s or %s",
+		level, strings.Join(ReportedLevels, ", "), LevelIgnore)
+}
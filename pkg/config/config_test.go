@@ -3,8 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"gopkg.in/yaml.v3"
 
 	"github.com/open-policy-agent/opa/ast"
@@ -358,3 +360,181 @@ func TestUnmarshalConfigDefaultCapabilities(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalConfigLevels(t *testing.T) {
+	t.Parallel()
+
+	bs := []byte(`rules:
+  style:
+    default:
+      level: info
+    prefer-snake-case:
+      level: hint
+`)
+
+	var conf Config
+
+	if err := yaml.Unmarshal(bs, &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.Defaults.Categories["style"].Level != LevelInfo {
+		t.Errorf("expected style default to be level info")
+	}
+
+	if conf.Rules["style"]["prefer-snake-case"].Level != LevelHint {
+		t.Errorf("expected prefer-snake-case to be level hint")
+	}
+}
+
+func TestUnmarshalConfigUnknownLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, bs := range []string{
+		"rules:\n  style:\n    prefer-snake-case:\n      level: critical\n",
+		"rules:\n  style:\n    default:\n      level: critical\n",
+		"rules:\n  default:\n    level: critical\n",
+	} {
+		var conf Config
+
+		err := yaml.Unmarshal([]byte(bs), &conf)
+		if err == nil || !strings.Contains(err.Error(), `unknown level "critical"`) {
+			t.Errorf("expected unknown level error for config:\n%s\ngot: %v", bs, err)
+		}
+	}
+}
+
+func TestIsMoreSevereOrEqual(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		level, other string
+		expected     bool
+	}{
+		{LevelError, LevelWarning, true},
+		{LevelWarning, LevelWarning, true},
+		{LevelInfo, LevelWarning, false},
+		{LevelHint, LevelInfo, false},
+		{LevelInfo, LevelHint, true},
+		{"unknown", LevelHint, false},
+	}
+
+	for _, tc := range cases {
+		if got := IsMoreSevereOrEqual(tc.level, tc.other); got != tc.expected {
+			t.Errorf("expected IsMoreSevereOrEqual(%s, %s) to be %t, got %t", tc.level, tc.other, tc.expected, got)
+		}
+	}
+}
+
+func TestUnmarshalConfigSchemas(t *testing.T) {
+	t.Parallel()
+
+	var conf Config
+
+	err := yaml.Unmarshal([]byte(`rules: {}
+schemas:
+  directory: schemas
+  input:
+    - package: kubernetes.admission
+      schema: k8s.admission
+    - entrypoint: policy.authz.allow
+      schema: schema.http.request
+`), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Schemas{
+		Directory: "schemas",
+		Input: []SchemaMapping{
+			{Package: "kubernetes.admission", Schema: "k8s.admission"},
+			{Entrypoint: "policy.authz.allow", Schema: "schema.http.request"},
+		},
+	}
+
+	if diff := cmp.Diff(expected, conf.Schemas); diff != "" {
+		t.Errorf("unexpected schemas (-want, +got):\n%s", diff)
+	}
+}
+
+func TestSchemasDirectoryRelativeToRegalDirectory(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":                   "",
+		"/.regal/config.yaml":          "schemas:\n  directory: schemas\n",
+		"/other/config.yaml":           "schemas:\n  directory: schemas\n",
+		"/absolute/.regal/config.yaml": "schemas:\n  directory: /schemas\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		directory := func(conf *Config) string {
+			t.Helper()
+
+			if conf == nil || conf.Schemas == nil {
+				t.Fatal("expected schemas to be configured")
+			}
+
+			return conf.Schemas.Directory
+		}
+
+		decoded := func(path string) *Config {
+			t.Helper()
+
+			file := testutil.Must(os.Open(path))(t)
+			defer file.Close()
+
+			var conf Config
+			if err := Decode(file, &conf); err != nil {
+				t.Fatal(err)
+			}
+
+			return &conf
+		}
+
+		expected := filepath.Join(root, "schemas")
+
+		if dir := directory(decoded(filepath.Join(root, ".regal", "config.yaml"))); dir != expected {
+			t.Errorf("expected %s, got %s", expected, dir)
+		}
+
+		resolved := testutil.Must(NewResolver().ForFile(filepath.Join(root, "policy", "p.rego")))(t)
+		if dir := directory(resolved); dir != expected {
+			t.Errorf("expected %s, got %s", expected, dir)
+		}
+
+		// config files outside of .regal directories, like those provided by --config-file, are their own base
+		expected = filepath.Join(root, "other", "schemas")
+
+		if dir := directory(decoded(filepath.Join(root, "other", "config.yaml"))); dir != expected {
+			t.Errorf("expected %s, got %s", expected, dir)
+		}
+
+		resolved = testutil.Must(NewResolverForConfigFile(filepath.Join(root, "other", "config.yaml"), root).
+			ForFile(filepath.Join(root, "p.rego")))(t)
+		if dir := directory(resolved); dir != expected {
+			t.Errorf("expected %s, got %s", expected, dir)
+		}
+
+		if dir := directory(decoded(filepath.Join(root, "absolute", ".regal", "config.yaml"))); dir != "/schemas" {
+			t.Errorf("expected absolute directory to be kept, got %s", dir)
+		}
+	})
+}
+
+func TestUnmarshalConfigInvalidSchemaMapping(t *testing.T) {
+	t.Parallel()
+
+	for bs, expected := range map[string]string{
+		"schemas:\n  input:\n    - schema: k8s.admission\n":                                  "exactly one of package or entrypoint",
+		"schemas:\n  input:\n    - package: p\n      entrypoint: p.allow\n      schema: s\n": "exactly one of package or entrypoint",
+		"schemas:\n  input:\n    - package: p\n":                                             "schema must be provided",
+	} {
+		var conf Config
+
+		err := yaml.Unmarshal([]byte(bs), &conf)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q for config:\n%s\ngot: %v", expected, bs, err)
+		}
+	}
+}
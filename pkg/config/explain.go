@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"gopkg.in/yaml.v3"
+
+	"github.com/styrainc/regal/internal/util"
+)
+
+// SourceProvided is the source of settings from the configuration provided by Regal, i.e. data.yaml in the
+// Regal bundle.
+const SourceProvided = "provided"
+
+const (
+	keyDefault = "default"
+	keyRules   = "rules"
+)
+
+// Setting is the value of a setting in the effective configuration, along with its source.
+type Setting struct {
+	Value  any    `json:"value"  yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+}
+
+// Explanation is the effective configuration of a file, i.e. the provided configuration merged with all layers of
+// user configuration applying to the file, where each setting is given along with its source.
+type Explanation struct {
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Sources are the sources of the layers of configuration merged, in the order merged.
+	Sources []string `json:"sources" yaml:"sources"`
+	// Default is the default for all rules, like the level, and CategoryDefaults those of each category, if any.
+	Default          map[string]Setting            `json:"default,omitempty"           yaml:"default,omitempty"`
+	CategoryDefaults map[string]map[string]Setting `json:"category-defaults,omitempty" yaml:"category-defaults,omitempty"`
+	// Rules are the settings of each rule, like level, ignore and any rule specific settings, by category and rule.
+	Rules map[string]map[string]map[string]Setting `json:"rules" yaml:"rules"`
+	// Settings are the settings other than those of rules, like ignore and capabilities, as provided by users.
+	Settings map[string]Setting `json:"settings,omitempty" yaml:"settings,omitempty"`
+}
+
+// Explain returns the effective configuration resulting from merging the provided configuration of regalBundle
+// with the layers of user configuration, as returned by Resolver.Layers, in order.
+func Explain(regalBundle *bundle.Bundle, layers []Layer) (*Explanation, error) {
+	raw := make(map[string]any)
+	sources := []string{SourceProvided}
+
+	for _, layer := range layers {
+		raw = mergeSettings(raw, layer.Settings)
+		sources = append(sources, layer.Source)
+	}
+
+	var userConfig *Config
+
+	if len(layers) > 0 {
+		bs, err := yaml.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal merged config: %w", err)
+		}
+
+		userConfig = &Config{}
+
+		if err := yaml.Unmarshal(bs, userConfig); err != nil {
+			return nil, fmt.Errorf("failed to decode merged config: %w", err)
+		}
+	}
+
+	merged, err := LoadConfigWithDefaultsFromBundle(regalBundle, userConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	provided, err := util.SearchMap(regalBundle.Data, []string{"regal", "config", "provided"})
+	if err != nil {
+		return nil, fmt.Errorf("provided config not found: %w", err)
+	}
+
+	providedMap, ok := provided.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected provided config to be an object")
+	}
+
+	explanation := &Explanation{
+		Sources:  sources,
+		Rules:    make(map[string]map[string]map[string]Setting),
+		Settings: make(map[string]Setting),
+	}
+
+	rules, _ := ToMap(merged)[keyRules].(map[string]any)
+
+	for category, rawCategory := range rules {
+		categoryRules, _ := rawCategory.(map[string]any)
+		explanation.Rules[category] = make(map[string]map[string]Setting, len(categoryRules))
+
+		for title, rawRule := range categoryRules {
+			ruleSettings, _ := rawRule.(map[string]any)
+			explained := make(map[string]Setting, len(ruleSettings))
+
+			_, err := util.SearchMap(providedMap, []string{keyRules, category, title})
+			isProvided := err == nil
+
+			for key, value := range ruleSettings {
+				source := lastSource(layers, keyRules, category, title, key)
+
+				switch {
+				case key == keyLevel && isProvided:
+					source = levelSource(layers, category, title)
+				case source == "":
+					source = SourceProvided
+				}
+
+				explained[key] = Setting{Value: value, Source: source}
+			}
+
+			explanation.Rules[category][title] = explained
+		}
+	}
+
+	rawRules, _ := raw[keyRules].(map[string]any)
+
+	for key, value := range rawRules {
+		if key == keyDefault {
+			explanation.Default = explainDefault(layers, value)
+
+			continue
+		}
+
+		if rawCategory, ok := value.(map[string]any); ok && rawCategory[keyDefault] != nil {
+			if explanation.CategoryDefaults == nil {
+				explanation.CategoryDefaults = make(map[string]map[string]Setting)
+			}
+
+			explanation.CategoryDefaults[key] = explainDefault(layers, rawCategory[keyDefault], key)
+		}
+	}
+
+	for key, value := range raw {
+		if key == keyRules || key == keyOverrides {
+			continue
+		}
+
+		explanation.Settings[key] = Setting{Value: value, Source: lastSource(layers, key)}
+	}
+
+	return explanation, nil
+}
+
+// explainDefault returns the settings of a default, either for all rules, or for the category given, if any.
+func explainDefault(layers []Layer, value any, category ...string) map[string]Setting {
+	settings, _ := value.(map[string]any)
+	explained := make(map[string]Setting, len(settings))
+
+	for key, setting := range settings {
+		path := append(append([]string{keyRules}, category...), keyDefault, key)
+		explained[key] = Setting{Value: setting, Source: lastSource(layers, path...)}
+	}
+
+	return explained
+}
+
+// levelSource returns the source of the level of a provided rule, following the precedence of levels when the
+// provided configuration is merged with user configuration: the level of the rule set by users, the default level
+// set for the category, the default level set for all rules, and lastly the provided level.
+func levelSource(layers []Layer, category, title string) string {
+	if source := lastSource(layers, keyRules, category, title, keyLevel); source != "" {
+		return source
+	}
+
+	// like when merging, a category default without a level leaves the provided level in place
+	if lastSource(layers, keyRules, category, keyDefault) != "" {
+		if source := lastSource(layers, keyRules, category, keyDefault, keyLevel); source != "" {
+			return "category default from " + source
+		}
+
+		return SourceProvided
+	}
+
+	if source := lastSource(layers, keyRules, keyDefault, keyLevel); source != "" {
+		return "global default from " + source
+	}
+
+	return SourceProvided
+}
+
+// lastSource returns the source of the last of layers setting the value at path, or an empty string if none do.
+func lastSource(layers []Layer, path ...string) string {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if _, err := util.SearchMap(layers[i].Settings, path); err == nil {
+			return layers[i].Source
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	keyExtends   = "extends"
+	presetPrefix = "regal:"
+)
+
+// presets are the configurations provided by Regal for extending, referenced by their file name, without extension,
+// and prefixed with regal:, e.g. regal:recommended.
+//
+//go:embed presets/*.yaml
+var presets embed.FS
+
+// Presets returns the names of the presets available for extending, e.g. regal:recommended.
+func Presets() []string {
+	entries, _ := presets.ReadDir("presets")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, presetPrefix+strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+	}
+
+	return names
+}
+
+// Decode decodes the config read from file into conf, where any configs extended using relative paths are
+// read relative to the directory of file. Like a YAML decoder, io.EOF is returned if file is empty. If the config
+// is invalid, ValidationErrors located in file are returned.
+func Decode(file *os.File, conf *Config) error {
+	var doc yaml.Node
+
+	if err := yaml.NewDecoder(file).Decode(&doc); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if len(doc.Content) == 0 {
+		return io.EOF
+	}
+
+	if errs := Validate(&doc, nil); len(errs) > 0 {
+		return errs.WithFile(file.Name())
+	}
+
+	return conf.unmarshal(doc.Content[0], filepath.Dir(file.Name()), nil)
+}
+
+// resolveExtends returns settings merged on top of the configs listed under extends in settings, each merged on
+// top of the previous one, and on top of those it extends in turn. Relative paths to configs are read relative to
+// dir, and validated against known. The extends key itself is removed from the settings returned.
+func resolveExtends(settings map[string]any, dir string, known KnownRules) (map[string]any, error) {
+	layers, err := extendedLayers(settings, "", dir, nil, known)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]any)
+
+	for _, layer := range layers {
+		merged = mergeSettings(merged, layer.Settings)
+	}
+
+	return merged, nil
+}
+
+// extendedLayers returns the layers of the configs extended by settings, in the order to be merged, followed by
+// settings from source itself, without the extends key. The chain is that of the configs extending settings, and
+// configs extended are validated against known.
+func extendedLayers(settings map[string]any, source, dir string, chain []string, known KnownRules) ([]Layer, error) {
+	refs, err := extendsRefs(settings[keyExtends])
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]Layer, 0, len(refs)+1)
+
+	for _, ref := range refs {
+		base, baseDir, id, err := readExtended(ref, dir, known)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(chain, id) {
+			return nil, fmt.Errorf("config %s extends itself", ref)
+		}
+
+		if _, ok := base[keyOverrides]; ok {
+			return nil, fmt.Errorf("extended config %s can't have overrides", ref)
+		}
+
+		baseLayers, err := extendedLayers(base, id, baseDir, append(slices.Clone(chain), id), known)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extend %s: %w", ref, err)
+		}
+
+		layers = append(layers, baseLayers...)
+	}
+
+	own := make(map[string]any, len(settings))
+
+	for key, value := range settings {
+		if key != keyExtends {
+			own[key] = value
+		}
+	}
+
+	return append(layers, Layer{Source: source, Settings: own}), nil
+}
+
+// extendsRefs returns the configs listed under extends, which is either a single config or a list of them.
+func extendsRefs(value any) ([]string, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []any:
+		refs := make([]string, 0, len(value))
+
+		for _, item := range value {
+			ref, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of configs, found %v", keyExtends, item)
+			}
+
+			refs = append(refs, ref)
+		}
+
+		return refs, nil
+	}
+
+	return nil, fmt.Errorf("%s must be a config or a list of configs, found %v", keyExtends, value)
+}
+
+// readExtended reads the config referenced by ref, either a preset or a path relative to dir, and returns its
+// settings, the directory relative to which configs extended by it are read, and an ID identifying the config.
+// The config is validated against known, like Validate.
+func readExtended(ref, dir string, known KnownRules) (map[string]any, string, string, error) {
+	var (
+		bs  []byte
+		err error
+		id  = ref
+	)
+
+	if name, ok := strings.CutPrefix(ref, presetPrefix); ok {
+		bs, err = presets.ReadFile("presets/" + name + ".yaml")
+		if err != nil {
+			return nil, "", "", fmt.Errorf("unknown preset %s, available presets are: %s",
+				ref, strings.Join(Presets(), ", "))
+		}
+	} else {
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(dir, ref)
+		}
+
+		if id, err = filepath.Abs(ref); err != nil {
+			return nil, "", "", fmt.Errorf("failed to get absolute path of %s: %w", ref, err)
+		}
+
+		bs, err = os.ReadFile(ref)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("failed to read extended config: %w", err)
+		}
+
+		dir = filepath.Dir(ref)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, "", "", fmt.Errorf("failed to parse extended config %s: %w", ref, err)
+	}
+
+	if errs := Validate(&doc, known); len(errs) > 0 {
+		return nil, "", "", errs.WithFile(id)
+	}
+
+	settings := make(map[string]any)
+
+	if err := doc.Decode(&settings); err != nil {
+		return nil, "", "", fmt.Errorf("failed to parse extended config %s: %w", ref, err)
+	}
+
+	return settings, dir, id, nil
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-policy-agent/opa/util/test"
+
+	"github.com/styrainc/regal/internal/testutil"
+)
+
+func TestDecodeExtends(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/base.yaml": `rules:
+  style:
+    line-length:
+      level: warning
+      max-line-length: 80
+ignore:
+  files:
+    - generated.rego
+`,
+		"/.regal/config.yaml": `extends:
+  - regal:all
+  - ../base.yaml
+rules:
+  style:
+    line-length:
+      level: error
+`,
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		file := testutil.Must(os.Open(filepath.Join(root, ".regal", "config.yaml")))(t)
+		defer file.Close()
+
+		var conf Config
+		if err := Decode(file, &conf); err != nil {
+			t.Fatal(err)
+		}
+
+		if exp := []string{"regal:all", "../base.yaml"}; !slices.Equal(conf.Extends, exp) {
+			t.Errorf("expected extends %v, got %v", exp, conf.Extends)
+		}
+
+		lineLength := conf.Rules["style"]["line-length"]
+		if lineLength.Level != levelError || lineLength.Extra["max-line-length"] != 80 {
+			t.Errorf("expected line-length level error and max-line-length 80, got %v", lineLength)
+		}
+
+		if conf.Defaults.Global.Level != levelError {
+			t.Errorf("expected global default level error from regal:all, got %q", conf.Defaults.Global.Level)
+		}
+
+		if !slices.Equal(conf.Ignore.Files, []string{"generated.rego"}) {
+			t.Errorf("expected ignored files from base config, got %v", conf.Ignore.Files)
+		}
+
+		// the resolved config is printed without extends
+		bs := testutil.Must(yaml.Marshal(conf))(t)
+		if strings.Contains(string(bs), keyExtends) {
+			t.Errorf("expected marshalled config without extends, got:\n%s", bs)
+		}
+
+		if _, ok := ToMap(conf)[keyExtends]; ok {
+			t.Error("expected config map without extends")
+		}
+	})
+}
+
+func TestUnmarshalConfigExtendsPreset(t *testing.T) {
+	t.Parallel()
+
+	var conf Config
+	if err := yaml.Unmarshal([]byte("extends: regal:strict\n"), &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if level := conf.Rules["bugs"]["unused-rule"].Level; level != levelError {
+		t.Errorf("expected unused-rule level error, got %q", level)
+	}
+
+	if maxWarnings := conf.Defaults.Global.MaxWarnings; maxWarnings == nil || *maxWarnings != 0 {
+		t.Errorf("expected max-warnings 0, got %v", maxWarnings)
+	}
+}
+
+func TestUnmarshalConfigExtendsErrors(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/a.yaml":         "extends: b.yaml\n",
+		"/b.yaml":         "extends: a.yaml\n",
+		"/overrides.yaml": "overrides:\n  \"*.rego\": {}\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		cases := map[string]string{
+			"extends: regal:unknown\n":                                 "unknown preset regal:unknown",
+			"extends: " + filepath.Join(root, "a.yaml") + "\n":         "extends itself",
+			"extends: " + filepath.Join(root, "overrides.yaml") + "\n": "can't have overrides",
+			"extends: " + filepath.Join(root, "missing.yaml") + "\n":   "failed to read extended config",
+			"extends: {preset: regal:all}\n":                           "must be a config or a list of configs",
+		}
+
+		for doc, expected := range cases {
+			var conf Config
+
+			err := yaml.Unmarshal([]byte(doc), &conf)
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected error containing %q, got %v", strings.TrimSpace(doc), expected, err)
+			}
+		}
+	})
+}
+
+func TestResolverForFileExtends(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":                  "",
+		"/shared/regal.yaml":          "rules:\n  style:\n    todo-comment:\n      level: ignore\n",
+		"/.regal/config.yaml":         "extends: ../shared/regal.yaml\n",
+		"/foo/.regal/config.yaml":     "rules:\n  style:\n    line-length:\n      level: warning\n",
+		"/foo/.regal/rules/rule.rego": "",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		conf := testutil.Must(NewResolver().ForFile(filepath.Join(root, "foo", "p.rego")))(t)
+
+		if level := conf.Rules["style"]["todo-comment"].Level; level != LevelIgnore {
+			t.Errorf("expected todo-comment level ignore from extended config, got %q", level)
+		}
+
+		if level := conf.Rules["style"]["line-length"].Level; level != LevelWarning {
+			t.Errorf("expected line-length level warning, got %q", level)
+		}
+	})
+}
+
+func TestMarshalConfigExtendsPreset(t *testing.T) {
+	t.Parallel()
+
+	var conf Config
+	if err := yaml.Unmarshal([]byte("extends: regal:strict\n"), &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped Config
+	if err := yaml.Unmarshal(testutil.Must(yaml.Marshal(conf))(t), &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+
+	if maxWarnings := roundTripped.Defaults.Global.MaxWarnings; maxWarnings == nil || *maxWarnings != 0 {
+		t.Errorf("expected max-warnings 0 after round trip, got %v", maxWarnings)
+	}
+
+	if level := roundTripped.Rules["custom"]["one-liner-rule"].Level; level != levelError {
+		t.Errorf("expected one-liner-rule level error after round trip, got %q", level)
+	}
+}
@@ -0,0 +1,412 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v3"
+)
+
+const keyOverrides = "overrides"
+
+// FindConfigFiles returns the paths of the config files applying to files in dir, i.e. the config.yaml file of
+// each .regal directory found in dir and its parent directories, up to the root of the repository, ordered from
+// the root of the repository down to dir. The root of the repository is the nearest directory containing a .git
+// directory, but if no config file is found before reaching it, the search continues in the parent directories
+// until one is found, as with FindRegalDirectory.
+func FindConfigFiles(dir string) []string {
+	found := make([]string, 0)
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return found
+	}
+
+	for {
+		path := filepath.Join(dir, regalDirName, configFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, path)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && len(found) > 0 {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	slices.Reverse(found)
+
+	return found
+}
+
+// Resolver resolves the user configuration of files by merging all config files applying to each file, as found
+// by FindConfigFiles, where settings in config files nearer to the file take precedence over those further up.
+// Config files may further contain overrides, keyed by a glob pattern matched against the path of the file
+// relative to the directory containing the .regal directory:
+//
+//	overrides:
+//	  "legacy/**":
+//	    rules:
+//	      style:
+//	        line-length:
+//	          level: ignore
+//
+// Overrides take precedence over the other settings of the config file declaring them, but not over config files
+// nearer to the file. When more than one override in a config file matches a file, later ones take precedence.
+type Resolver struct {
+	// configFile, if set, is the only config file used, with overrides relative to configDir
+	configFile string
+	configDir  string
+
+	mu      sync.Mutex
+	files   map[string]*configFile
+	configs map[string]*Config
+}
+
+// Layer is a set of settings merged into the configuration of a file, along with their source, i.e. the path of
+// a config file, a preset, or an override in a config file. The settings are kept as read from YAML, as a setting
+// left out is not the same as the default value of the setting, e.g. for capabilities.
+type Layer struct {
+	Source   string
+	Settings map[string]any
+}
+
+// configFile is a config file read for merging with others, where the layers are those of the configs extended
+// by the file, followed by the settings of the file itself. The modification time of each config file extended is
+// kept along with that of the file, as the file must be read again when any of them change.
+type configFile struct {
+	modTime   time.Time
+	extended  map[string]time.Time
+	dir       string
+	settings  map[string]any
+	layers    []Layer
+	overrides []override
+}
+
+type override struct {
+	pattern  string
+	glob     glob.Glob
+	settings map[string]any
+}
+
+// NewResolver returns a resolver merging the config files found in .regal directories applying to each file.
+func NewResolver() *Resolver {
+	return &Resolver{
+		files:   make(map[string]*configFile),
+		configs: make(map[string]*Config),
+	}
+}
+
+// NewResolverForConfigFile returns a resolver using only the config file at path, like one provided via the
+// --config-file flag, applying any overrides in it to files relative to dir.
+func NewResolverForConfigFile(path, dir string) *Resolver {
+	resolver := NewResolver()
+	resolver.configFile = path
+	resolver.configDir = dir
+
+	return resolver
+}
+
+// ForFile returns the user configuration applying to the file at path, or nil if no config file applies to it.
+func (r *Resolver) ForFile(path string) (*Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	layers, err := r.layers(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(layers) == 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	merged := make(map[string]any)
+
+	for _, layer := range layers {
+		merged = mergeSettings(merged, layer.Settings)
+	}
+
+	// files with the same settings share the same config, which saves decoding it again, and
+	// allows users of the resolver to recognize files sharing config
+	key, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged config: %w", err)
+	}
+
+	if conf, ok := r.configs[string(key)]; ok {
+		return conf, nil
+	}
+
+	bs, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged config: %w", err)
+	}
+
+	var conf Config
+
+	if err := yaml.Unmarshal(bs, &conf); err != nil {
+		sources := make([]string, 0, len(layers))
+		for _, layer := range layers {
+			sources = append(sources, layer.Source)
+		}
+
+		return nil, fmt.Errorf("failed to decode config merged from %s: %w", strings.Join(sources, ", "), err)
+	}
+
+	r.configs[string(key)] = &conf
+
+	return &conf, nil
+}
+
+// Layers returns the layers of settings merged into the user configuration of the file or directory at path, in
+// the order merged, or none if no config file applies to it.
+func (r *Resolver) Layers(path string) ([]Layer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.layers(path)
+}
+
+// ConfigFiles returns the paths of all config files read so far, including those extended, but not presets.
+func (r *Resolver) ConfigFiles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paths := make([]string, 0, len(r.files))
+
+	for path, file := range r.files {
+		paths = append(paths, path)
+
+		for _, layer := range file.layers {
+			if filepath.IsAbs(layer.Source) && !slices.Contains(paths, layer.Source) {
+				paths = append(paths, layer.Source)
+			}
+		}
+	}
+
+	slices.Sort(paths)
+
+	return slices.Compact(paths)
+}
+
+func (r *Resolver) layers(path string) ([]Layer, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dir = path
+	}
+
+	paths := []string{r.configFile}
+	if r.configFile == "" {
+		paths = FindConfigFiles(dir)
+	}
+
+	layers := make([]Layer, 0, len(paths))
+
+	for _, configPath := range paths {
+		file, err := r.read(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, file.layers...)
+
+		rel, err := filepath.Rel(file.dir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		for _, o := range file.overrides {
+			if o.glob.Match(filepath.ToSlash(rel)) {
+				layers = append(layers, Layer{
+					Source:   fmt.Sprintf("%s (overrides %q)", configPath, o.pattern),
+					Settings: o.settings,
+				})
+			}
+		}
+	}
+
+	return layers, nil
+}
+
+// read returns the config file at path, read again only if modified since last read.
+func (r *Resolver) read(path string) (*configFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat config file %s: %w", path, err)
+	}
+
+	if file, ok := r.files[path]; ok && file.modTime.Equal(info.ModTime()) && !file.extendedModified() {
+		return file, nil
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	dir := r.configDir
+	if dir == "" {
+		// the directory containing the .regal directory
+		dir = filepath.Dir(filepath.Dir(path))
+	}
+
+	file, err := parseConfigFile(bs, dir)
+	if err != nil {
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			return nil, errs.WithFile(path)
+		}
+
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	// configs extended are read relative to the directory of the config file
+	if file.layers, err = extendedLayers(file.settings, path, filepath.Dir(path), nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to extend config file %s: %w", path, err)
+	}
+
+	// a relative schemas directory is read relative to the directory holding the .regal directory
+	base := schemasBaseDir(filepath.Dir(path))
+
+	for i := range file.layers {
+		file.layers[i].Settings = withSchemasDirectory(file.layers[i].Settings, base)
+	}
+
+	for i := range file.overrides {
+		file.overrides[i].settings = withSchemasDirectory(file.overrides[i].settings, base)
+	}
+
+	file.modTime = info.ModTime()
+	file.extended = make(map[string]time.Time)
+
+	for _, layer := range file.layers {
+		// layers of presets have no path, and the last layer is that of the file itself
+		if !filepath.IsAbs(layer.Source) || layer.Source == path {
+			continue
+		}
+
+		if info, err := os.Stat(layer.Source); err == nil {
+			file.extended[layer.Source] = info.ModTime()
+		}
+	}
+
+	r.files[path] = file
+
+	return file, nil
+}
+
+// extendedModified returns true if any config file extended by f has been modified or removed since read.
+func (f *configFile) extendedModified() bool {
+	for path, modTime := range f.extended {
+		if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseConfigFile(bs []byte, dir string) (*configFile, error) {
+	file := &configFile{dir: dir, settings: make(map[string]any)}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	// empty file
+	if len(doc.Content) == 0 {
+		return file, nil
+	}
+
+	if errs := Validate(&doc, nil); len(errs) > 0 {
+		return nil, errs
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("config must be a map")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+
+		if key != keyOverrides {
+			var setting any
+			if err := value.Decode(&setting); err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", key, err)
+			}
+
+			file.settings[key] = setting
+
+			continue
+		}
+
+		if value.Kind != yaml.MappingNode {
+			return nil, errors.New("overrides must be a map of glob patterns to config")
+		}
+
+		// overrides are decoded from the YAML nodes, as the order in which they are applied matters
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			pattern := value.Content[j].Value
+
+			g, err := glob.Compile(pattern, '/')
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile override pattern %q: %w", pattern, err)
+			}
+
+			var settings map[string]any
+			if err := value.Content[j+1].Decode(&settings); err != nil {
+				return nil, fmt.Errorf("failed to decode override %q: %w", pattern, err)
+			}
+
+			file.overrides = append(file.overrides, override{pattern: pattern, glob: g, settings: settings})
+		}
+	}
+
+	return file, nil
+}
+
+// mergeSettings returns the settings of base merged with those of other, where maps are merged recursively,
+// and any other value in other replaces the value in base.
+func mergeSettings(base, other map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(other))
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range other {
+		baseMap, baseOk := merged[key].(map[string]any)
+		otherMap, otherOk := value.(map[string]any)
+
+		if baseOk && otherOk {
+			merged[key] = mergeSettings(baseMap, otherMap)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
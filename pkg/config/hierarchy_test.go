@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/open-policy-agent/opa/util/test"
+
+	"github.com/styrainc/regal/internal/testutil"
+)
+
+func TestFindConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":                    "",
+		"/.regal/config.yaml":           "",
+		"/foo/.regal/config.yaml":       "",
+		"/foo/bar/baz/p.rego":           "",
+		"/other/.regal/rules/rule.rego": "",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		found := FindConfigFiles(filepath.Join(root, "foo", "bar", "baz"))
+		expected := []string{
+			filepath.Join(root, ".regal", "config.yaml"),
+			filepath.Join(root, "foo", ".regal", "config.yaml"),
+		}
+
+		if !slices.Equal(found, expected) {
+			t.Errorf("expected %v, got %v", expected, found)
+		}
+
+		// a .regal directory without a config file doesn't count
+		found = FindConfigFiles(filepath.Join(root, "other"))
+		expected = []string{filepath.Join(root, ".regal", "config.yaml")}
+
+		if !slices.Equal(found, expected) {
+			t.Errorf("expected %v, got %v", expected, found)
+		}
+	})
+}
+
+func TestResolverForFile(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD": "",
+		"/.regal/config.yaml": `rules:
+  style:
+    line-length:
+      level: error
+      max-line-length: 100
+    prefer-snake-case:
+      level: error
+overrides:
+  "legacy/**":
+    rules:
+      style:
+        line-length:
+          level: ignore
+  "foo/**":
+    rules:
+      style:
+        prefer-snake-case:
+          level: ignore
+`,
+		"/foo/.regal/config.yaml": `rules:
+  style:
+    line-length:
+      max-line-length: 80
+    prefer-snake-case:
+      level: warning
+overrides:
+  "*_test.rego":
+    rules:
+      style:
+        line-length:
+          level: ignore
+`,
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		resolver := NewResolver()
+
+		forFile := func(path string) *Config {
+			t.Helper()
+
+			return testutil.Must(resolver.ForFile(filepath.Join(root, path)))(t)
+		}
+
+		cases := []struct {
+			path            string
+			lineLength      string
+			maxLineLength   any
+			preferSnakeCase string
+		}{
+			{"p.rego", "error", 100, "error"},
+			{"legacy/sub/p.rego", "ignore", 100, "error"},
+			// nearer config files take precedence over overrides in config files further up
+			{"foo/p.rego", "error", 80, "warning"},
+			{"foo/p_test.rego", "ignore", 80, "warning"},
+			{"foo/sub/p_test.rego", "error", 80, "warning"},
+		}
+
+		for _, tc := range cases {
+			conf := forFile(tc.path)
+			if conf == nil {
+				t.Fatalf("expected config for %s", tc.path)
+			}
+
+			lineLength := conf.Rules["style"]["line-length"]
+
+			if lineLength.Level != tc.lineLength {
+				t.Errorf("%s: expected line-length level %s, got %s", tc.path, tc.lineLength, lineLength.Level)
+			}
+
+			if lineLength.Extra["max-line-length"] != tc.maxLineLength {
+				t.Errorf("%s: expected max-line-length %v, got %v",
+					tc.path, tc.maxLineLength, lineLength.Extra["max-line-length"])
+			}
+
+			if level := conf.Rules["style"]["prefer-snake-case"].Level; level != tc.preferSnakeCase {
+				t.Errorf("%s: expected prefer-snake-case level %s, got %s", tc.path, tc.preferSnakeCase, level)
+			}
+		}
+
+		// files with the same settings share the same config
+		if forFile("p.rego") != forFile("other/p.rego") {
+			t.Error("expected files with the same settings to share config")
+		}
+	})
+}
+
+func TestResolverForFileWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	test.WithTempFS(map[string]string{"/.git/HEAD": "", "/p.rego": ""}, func(root string) {
+		if _, err := NewResolverForConfigFile(filepath.Join(root, "missing.yaml"), root).
+			ForFile(filepath.Join(root, "p.rego")); err == nil {
+			t.Error("expected error for missing config file")
+		}
+	})
+}
+
+func TestResolverConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":              "",
+		"/.regal/config.yaml":     "extends: [regal:recommended, base.yaml]\n",
+		"/.regal/base.yaml":       "rules: {}\n",
+		"/foo/.regal/config.yaml": "rules: {}\n",
+		"/bar/.regal/config.yaml": "rules: {}\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		resolver := NewResolver()
+
+		if _, err := resolver.ForFile(filepath.Join(root, "foo", "p.rego")); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{
+			filepath.Join(root, ".regal", "base.yaml"),
+			filepath.Join(root, ".regal", "config.yaml"),
+			filepath.Join(root, "foo", ".regal", "config.yaml"),
+		}
+
+		if files := resolver.ConfigFiles(); !slices.Equal(files, expected) {
+			t.Errorf("expected %v, got %v", expected, files)
+		}
+	})
+}
+
+func TestResolverReadsModifiedExtendedConfig(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":          "",
+		"/.regal/config.yaml": "extends: base.yaml\n",
+		"/.regal/base.yaml":   "rules:\n  style:\n    line-length:\n      level: error\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		resolver := NewResolver()
+		path := filepath.Join(root, "p.rego")
+
+		if conf := testutil.Must(resolver.ForFile(path))(t); conf.Rules["style"]["line-length"].Level != "error" {
+			t.Fatalf("expected level error, got %q", conf.Rules["style"]["line-length"].Level)
+		}
+
+		// only the extended config is modified
+		base := filepath.Join(root, ".regal", "base.yaml")
+		modified := "rules:\n  style:\n    line-length:\n      level: warning\n"
+
+		if err := os.WriteFile(base, []byte(modified), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(base, later, later); err != nil {
+			t.Fatal(err)
+		}
+
+		if conf := testutil.Must(resolver.ForFile(path))(t); conf.Rules["style"]["line-length"].Level != "warning" {
+			t.Errorf("expected level warning after modifying extended config, got %q",
+				conf.Rules["style"]["line-length"].Level)
+		}
+	})
+}
+
+func TestResolverInvalidOverrides(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/.git/HEAD":          "",
+		"/.regal/config.yaml": "overrides:\n  - rules: {}\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		if _, err := NewResolver().ForFile(filepath.Join(root, "p.rego")); err == nil {
+			t.Error("expected error for overrides not being a map")
+		}
+	})
+}
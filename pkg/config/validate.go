@@ -0,0 +1,566 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/agnivade/levenshtein"
+	"gopkg.in/yaml.v3"
+
+	rbundle "github.com/styrainc/regal/bundle"
+)
+
+// Kinds of values of rule attributes, as derived from the values of the provided configuration.
+const (
+	KindString  = "a string"
+	KindInteger = "an integer"
+	KindNumber  = "a number"
+	KindBoolean = "a boolean"
+	KindList    = "a list"
+	KindObject  = "an object"
+)
+
+// ValidationError is an error found in user configuration, located at the line and column of the value in error.
+// UnknownRule is set for errors reporting a category or rule not known, which regal lint reports as warnings only,
+// as the configuration may be meant for another version of Regal.
+type ValidationError struct {
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Message     string `json:"message"`
+	UnknownRule bool   `json:"unknown_rule,omitempty"`
+}
+
+func (e ValidationError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ValidationErrors are all errors found when validating user configuration, in the order found in the config file.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// WithFile returns the errors with the file set to file.
+func (e ValidationErrors) WithFile(file string) ValidationErrors {
+	errs := make(ValidationErrors, 0, len(e))
+	for _, err := range e {
+		err.File = file
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// KnownRules are the rules known to Regal, i.e. built-in rules and any custom rules loaded, by category and title,
+// mapped to the kind of value of each of their attributes, if known. Attributes are known for built-in rules only,
+// and common attributes like level and ignore are valid for any rule and not included.
+type KnownRules map[string]map[string]map[string]string
+
+// Add adds a rule with unknown attributes, like a custom rule, unless already known.
+func (k KnownRules) Add(category, title string) {
+	if _, ok := k[category]; !ok {
+		k[category] = make(map[string]map[string]string)
+	}
+
+	if _, ok := k[category][title]; !ok {
+		k[category][title] = nil
+	}
+}
+
+// KnownRulesFromProvided returns the rules of the provided configuration, with the kind of each of their attributes
+// derived from the values provided.
+func KnownRulesFromProvided(provided map[string]any) KnownRules {
+	known := make(KnownRules)
+
+	rules, _ := provided[keyRules].(map[string]any)
+
+	for category, rawCategory := range rules {
+		categoryRules, _ := rawCategory.(map[string]any)
+		known[category] = make(map[string]map[string]string, len(categoryRules))
+
+		for title, rawRule := range categoryRules {
+			ruleSettings, _ := rawRule.(map[string]any)
+			attributes := make(map[string]string, len(ruleSettings))
+
+			for key, value := range ruleSettings {
+				if key != keyLevel && key != keyIgnore && key != keyMaxViolations {
+					attributes[key] = kindOf(value)
+				}
+			}
+
+			known[category][title] = attributes
+		}
+	}
+
+	return known
+}
+
+// builtinRules returns the rules of the configuration provided with Regal.
+//
+//nolint:gochecknoglobals
+var builtinRules = sync.OnceValue(func() KnownRules {
+	bs, err := rbundle.Bundle.ReadFile("regal/config/provided/data.yaml")
+	if err != nil {
+		return make(KnownRules)
+	}
+
+	var provided map[string]any
+	if err := yaml.Unmarshal(bs, &provided); err != nil {
+		return make(KnownRules)
+	}
+
+	known := KnownRulesFromProvided(provided)
+
+	for category, categoryRules := range optionalAttributes {
+		for title, attributes := range categoryRules {
+			for key, kind := range attributes {
+				if known[category][title] != nil {
+					known[category][title][key] = kind
+				}
+			}
+		}
+	}
+
+	return known
+})
+
+// optionalAttributes are attributes of built-in rules that are documented but not set in the provided
+// configuration, as the rules behave differently when they are absent.
+//
+//nolint:gochecknoglobals
+var optionalAttributes = KnownRules{
+	"custom": {
+		"naming-convention":    {"conventions": KindList},
+		"prefer-value-in-head": {"only-scalars": KindBoolean},
+	},
+	"imports": {
+		"prefer-package-imports": {"ignore-import-paths": KindList},
+		"unresolved-import":      {"except-imports": KindList},
+	},
+	"style": {
+		"line-length":              {"non-breakable-word-threshold": KindInteger},
+		"no-whitespace-comment":    {"except-pattern": KindString},
+		"prefer-some-in-iteration": {"ignore-if-sub-attribute": KindBoolean},
+	},
+}
+
+// BuiltinRules returns the built-in rules of Regal, with the kind of each of their attributes.
+func BuiltinRules() KnownRules {
+	known := make(KnownRules)
+
+	for category, categoryRules := range builtinRules() {
+		known[category] = make(map[string]map[string]string, len(categoryRules))
+
+		for title, attributes := range categoryRules {
+			known[category][title] = attributes
+		}
+	}
+
+	return known
+}
+
+// Validate validates the user configuration in node, returning all errors found. Unknown categories and rules are
+// reported only if known includes all rules loaded, like custom rules, as configuring rules not known to Regal is
+// otherwise allowed. In either case, attributes of built-in rules are checked against those of the provided config.
+func Validate(node *yaml.Node, known KnownRules) ValidationErrors {
+	v := &validator{known: known, reportUnknownRules: true}
+	if known == nil {
+		v.known = builtinRules()
+		v.reportUnknownRules = false
+	}
+
+	// an empty document, or no document at all
+	if node.Kind == 0 || node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+
+		node = node.Content[0]
+	}
+
+	v.config(node, false)
+
+	return v.errors
+}
+
+// ValidateFile validates the config file at path against known, like Validate, returning the errors found located
+// in the file. An error is returned if the file can't be read or parsed, or if the configs it extends are invalid.
+func ValidateFile(path string, known KnownRules) (ValidationErrors, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if errs := Validate(&doc, known); len(errs) > 0 {
+		return errs.WithFile(path), nil
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var conf Config
+	if err := conf.unmarshal(doc.Content[0], filepath.Dir(path), known); err != nil {
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			return errs, nil
+		}
+
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil, nil
+}
+
+type validator struct {
+	known              KnownRules
+	reportUnknownRules bool
+	errors             ValidationErrors
+}
+
+func (v *validator) errorf(node *yaml.Node, format string, args ...any) {
+	v.errors = append(v.errors, ValidationError{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// unknown reports key as unknown with message, suggesting the most similar of candidates, if any is similar enough.
+func (v *validator) unknown(key *yaml.Node, message string, candidates []string) {
+	suggestion, distance := "", 3
+
+	slices.Sort(candidates)
+
+	for _, candidate := range candidates {
+		if d := levenshtein.ComputeDistance(key.Value, candidate); d < distance {
+			suggestion, distance = candidate, d
+		}
+	}
+
+	if suggestion != "" {
+		v.errorf(key, "%s, did you mean %s?", message, suggestion)
+	} else {
+		v.errorf(key, "%s", message)
+	}
+}
+
+// unknownRule reports key as a category or rule not known, like unknown.
+func (v *validator) unknownRule(key *yaml.Node, message string, candidates []string) {
+	v.unknown(key, message, candidates)
+	v.errors[len(v.errors)-1].UnknownRule = true
+}
+
+// isMap returns true if node is a map, or null, which is allowed for any map in the config.
+func (v *validator) isMap(node *yaml.Node, what string) bool {
+	if node.Kind == yaml.MappingNode || node.Tag == "!!null" {
+		return true
+	}
+
+	v.errorf(node, "%s must be a map", what)
+
+	return false
+}
+
+// config validates a config, or the config of an override, which may not contain extends or overrides.
+func (v *validator) config(node *yaml.Node, override bool) {
+	if !v.isMap(node, "config") {
+		return
+	}
+
+	keys := []string{keyRules, keyIgnore, "capabilities", "features", "schemas", keyExtends, keyOverrides}
+
+	for _, entry := range mapEntries(node) {
+		key, value := entry[0], entry[1]
+
+		switch key.Value {
+		case keyRules:
+			v.rules(value)
+		case keyIgnore:
+			v.ignore(value, keyIgnore)
+		case "capabilities", "features", "schemas":
+			v.isMap(value, key.Value)
+		case keyExtends:
+			if override {
+				v.errorf(key, "%s is not supported in overrides", keyExtends)
+			} else {
+				v.extends(value)
+			}
+		case keyOverrides:
+			if override {
+				v.errorf(key, "%s are not supported in overrides", keyOverrides)
+			} else if v.isMap(value, keyOverrides) {
+				for _, entry := range mapEntries(value) {
+					_, settings := entry[0], entry[1]
+
+					v.config(settings, true)
+				}
+			}
+		default:
+			v.unknown(key, "unknown key "+key.Value, keys)
+		}
+	}
+}
+
+func (v *validator) extends(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		return
+	}
+
+	if node.Kind == yaml.SequenceNode {
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode || item.Tag != "!!str" {
+				v.errorf(item, "%s must be a list of configs", keyExtends)
+			}
+		}
+
+		return
+	}
+
+	v.errorf(node, "%s must be a config or a list of configs", keyExtends)
+}
+
+func (v *validator) ignore(node *yaml.Node, what string) {
+	if !v.isMap(node, what) {
+		return
+	}
+
+	for _, entry := range mapEntries(node) {
+		key, value := entry[0], entry[1]
+
+		if key.Value != "files" {
+			v.unknown(key, "unknown key "+key.Value+" in "+what, []string{"files"})
+
+			continue
+		}
+
+		if value.Kind != yaml.SequenceNode {
+			if value.Tag != "!!null" {
+				v.errorf(value, "%s.files must be a list of glob patterns", what)
+			}
+
+			continue
+		}
+
+		for _, item := range value.Content {
+			if item.Kind != yaml.ScalarNode || item.Tag != "!!str" {
+				v.errorf(item, "%s.files must be a list of glob patterns", what)
+			}
+		}
+	}
+}
+
+func (v *validator) rules(node *yaml.Node) {
+	if !v.isMap(node, keyRules) {
+		return
+	}
+
+	for _, entry := range mapEntries(node) {
+		key, value := entry[0], entry[1]
+		category := key.Value
+
+		if category == keyDefault {
+			v.defaults(value, "rules.default")
+
+			continue
+		}
+
+		knownRules, isKnown := v.known[category]
+		if !isKnown && v.reportUnknownRules {
+			v.unknownRule(key, "unknown category "+category, mapKeys(v.known))
+
+			continue
+		}
+
+		if !v.isMap(value, "rules."+category) {
+			continue
+		}
+
+		for _, entry := range mapEntries(value) {
+			ruleKey, ruleValue := entry[0], entry[1]
+			title := ruleKey.Value
+
+			if title == keyDefault {
+				v.defaults(ruleValue, "rules."+category+".default")
+
+				continue
+			}
+
+			attributes, isKnownRule := knownRules[title]
+			if !isKnownRule && isKnown && v.reportUnknownRules {
+				v.unknownRule(ruleKey, "unknown rule "+title+" in category "+category, mapKeys(knownRules))
+
+				continue
+			}
+
+			v.rule(ruleValue, category+"."+title, attributes)
+		}
+	}
+}
+
+func (v *validator) defaults(node *yaml.Node, what string) {
+	if !v.isMap(node, what) {
+		return
+	}
+
+	for _, entry := range mapEntries(node) {
+		key, value := entry[0], entry[1]
+
+		switch key.Value {
+		case keyLevel:
+			v.level(value)
+		case keyMaxWarnings:
+			v.budget(value, keyMaxWarnings)
+		default:
+			v.unknown(key, "unknown key "+key.Value+" in "+what, []string{keyLevel, keyMaxWarnings})
+		}
+	}
+}
+
+// rule validates the config of a rule, where other attributes than the common ones are checked only if the
+// attributes of the rule are known, i.e. for built-in rules.
+func (v *validator) rule(node *yaml.Node, name string, attributes map[string]string) {
+	if !v.isMap(node, "config of rule "+name) {
+		return
+	}
+
+	for _, entry := range mapEntries(node) {
+		key, value := entry[0], entry[1]
+
+		switch key.Value {
+		case keyLevel:
+			v.level(value)
+		case keyIgnore:
+			v.ignore(value, keyIgnore)
+		case keyMaxViolations:
+			v.budget(value, keyMaxViolations)
+		default:
+			if attributes == nil {
+				continue
+			}
+
+			kind, ok := attributes[key.Value]
+			if !ok {
+				v.unknown(key, "unknown attribute "+key.Value+" of rule "+name,
+					append(mapKeys(attributes), keyLevel, keyIgnore, keyMaxViolations))
+
+				continue
+			}
+
+			if actual := nodeKind(value); kind != "" && actual != kind &&
+				(kind != KindNumber || actual != KindInteger) && value.Tag != "!!null" {
+				v.errorf(value, "%s must be %s, got %s", key.Value, kind, actual)
+			}
+		}
+	}
+}
+
+func (v *validator) level(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		v.errorf(node, "level must be a string")
+
+		return
+	}
+
+	if err := validateLevel(node.Value); err != nil {
+		v.errorf(node, "%s", err)
+	}
+}
+
+func (v *validator) budget(node *yaml.Node, key string) {
+	var value any
+	if err := node.Decode(&value); err != nil {
+		v.errorf(node, "%s must be an integer", key)
+
+		return
+	}
+
+	if _, err := budgetValue(key, value); err != nil {
+		v.errorf(node, "%s", err)
+	}
+}
+
+// mapEntries returns the keys and values of a map node, in the order of the config file.
+func mapEntries(node *yaml.Node) [][2]*yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	entries := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		entries = append(entries, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+
+	return entries
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func kindOf(value any) string {
+	switch value.(type) {
+	case string:
+		return KindString
+	case int, int64, uint64:
+		return KindInteger
+	case float64:
+		return KindNumber
+	case bool:
+		return KindBoolean
+	case []any:
+		return KindList
+	case map[string]any:
+		return KindObject
+	}
+
+	return ""
+}
+
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return KindList
+	case yaml.MappingNode:
+		return KindObject
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!int":
+			return KindInteger
+		case "!!float":
+			return KindNumber
+		case "!!bool":
+			return KindBoolean
+		case "!!null":
+			return "null"
+		}
+	}
+
+	return KindString
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-policy-agent/opa/util/test"
+
+	"github.com/styrainc/regal/internal/testutil"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		config   string
+		expected []string
+	}{
+		"valid": {
+			config: `rules:
+  default:
+    level: warning
+    max-warnings: 3
+  style:
+    default:
+      level: error
+    line-length:
+      level: error
+      max-line-length: 100
+      non-breakable-word-threshold: 80
+      ignore:
+        files:
+          - gen/*.rego
+  custom:
+    naming-convention:
+      conventions:
+        - pattern: '^_'
+          targets: [rule]
+  my-category:
+    my-rule:
+      level: error
+      anything: goes
+ignore:
+  files: [generated.rego]
+capabilities:
+  from:
+    engine: opa
+`,
+		},
+		"empty":      {config: ""},
+		"null rules": {config: "rules:\n"},
+		"unknown key": {
+			config:   "rules: {}\nignroe:\n  files: []\n",
+			expected: []string{"2:1: unknown key ignroe, did you mean ignore?"},
+		},
+		"invalid level": {
+			config:   "rules:\n  style:\n    line-length:\n      level: critical\n",
+			expected: []string{`4:14: unknown level "critical"`},
+		},
+		"unknown attribute": {
+			config:   "rules:\n  style:\n    line-length:\n      max-line-lenght: 80\n",
+			expected: []string{"4:7: unknown attribute max-line-lenght of rule style.line-length, did you mean max-line-length?"},
+		},
+		"wrong attribute type": {
+			config:   "rules:\n  style:\n    line-length:\n      max-line-length: \"80\"\n",
+			expected: []string{"4:24: max-line-length must be an integer, got a string"},
+		},
+		"negative max-violations": {
+			config:   "rules:\n  style:\n    line-length:\n      max-violations: -1\n",
+			expected: []string{"4:23: max-violations must not be negative, got -1"},
+		},
+		"unknown default key": {
+			config:   "rules:\n  default:\n    max-warnigs: 3\n",
+			expected: []string{"3:5: unknown key max-warnigs in rules.default, did you mean max-warnings?"},
+		},
+		"ignore files not a list": {
+			config:   "ignore:\n  files: generated.rego\n",
+			expected: []string{"2:10: ignore.files must be a list of glob patterns"},
+		},
+		"extends in overrides": {
+			config:   "overrides:\n  \"*.rego\":\n    extends: regal:all\n",
+			expected: []string{"3:5: extends is not supported in overrides"},
+		},
+		"multiple errors": {
+			config:   "rules:\n  style:\n    line-length:\n      level: 1\nfoo: bar\n",
+			expected: []string{"4:14: level must be a string", "5:1: unknown key foo"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.config), &doc); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := Validate(&doc, nil)
+
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got %d: %v", len(tc.expected), len(errs), errs)
+			}
+
+			for i, expected := range tc.expected {
+				if !strings.HasPrefix(errs[i].Error(), expected) {
+					t.Errorf("expected error starting with %q, got %q", expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUnknownRules(t *testing.T) {
+	t.Parallel()
+
+	known := BuiltinRules()
+	known.Add("my-category", "my-rule")
+
+	config := `rules:
+  my-category:
+    my-rule:
+      level: error
+      anything: goes
+    my-rul:
+      level: error
+  styel:
+    line-length:
+      level: error
+`
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(config), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"6:5: unknown rule my-rul in category my-category, did you mean my-rule?",
+		"8:3: unknown category styel, did you mean style?",
+	}
+
+	errs := Validate(&doc, known)
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+
+	for i, exp := range expected {
+		if errs[i].Error() != exp {
+			t.Errorf("expected %q, got %q", exp, errs[i].Error())
+		}
+
+		if !errs[i].UnknownRule {
+			t.Errorf("expected %q to be reported as an unknown rule", exp)
+		}
+	}
+}
+
+func TestUnmarshalConfigValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	var conf Config
+
+	err := yaml.Unmarshal([]byte("rules:\n  style:\n    line-length:\n      max-line-length: long\n"), &conf)
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Line != 4 || errs[0].Column != 24 {
+		t.Fatalf("expected validation error at 4:24, got %v", err)
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	t.Parallel()
+
+	fs := map[string]string{
+		"/base.yaml":          "rules:\n  style:\n    todo-comment:\n      levle: error\n",
+		"/.regal/config.yaml": "extends: ../base.yaml\n",
+		"/valid.yaml":         "rules:\n  style:\n    todo-comment:\n      level: error\n",
+	}
+
+	test.WithTempFS(fs, func(root string) {
+		errs := testutil.Must(ValidateFile(filepath.Join(root, "valid.yaml"), BuiltinRules()))(t)
+		if len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+
+		// errors in extended configs are located in the config extended
+		errs = testutil.Must(ValidateFile(filepath.Join(root, ".regal", "config.yaml"), BuiltinRules()))(t)
+		if len(errs) != 1 || errs[0].File != filepath.Join(root, "base.yaml") || errs[0].Line != 4 {
+			t.Errorf("expected error on line 4 of base.yaml, got %v", errs)
+		}
+	})
+}
+
+// TestValidateRuleDocsExamples validates the configuration examples in the documentation of each rule, so
+// that users copying them get a valid configuration.
+func TestValidateRuleDocsExamples(t *testing.T) {
+	t.Parallel()
+
+	docs, err := filepath.Glob(filepath.Join("..", "..", "docs", "rules", "*", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(docs) == 0 {
+		t.Fatal("expected to find rule docs")
+	}
+
+	yamlBlock := regexp.MustCompile("(?s)```yaml\n(.*?)```")
+
+	for _, doc := range docs {
+		content := testutil.Must(os.ReadFile(doc))(t)
+
+		for _, block := range yamlBlock.FindAllSubmatch(content, -1) {
+			var node yaml.Node
+			if err := yaml.Unmarshal(block[1], &node); err != nil {
+				t.Errorf("%s: failed to parse configuration example: %v", doc, err)
+
+				continue
+			}
+
+			for _, err := range Validate(&node, BuiltinRules()) {
+				t.Errorf("%s: invalid configuration example: %v", doc, err)
+			}
+		}
+	}
+}
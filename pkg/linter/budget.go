@@ -0,0 +1,142 @@
+package linter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// Budgets returns the violation budgets configured in conf, with the number of violations counted against
+// each of them. Budgets are returned in the order global, category and rule budgets, sorted by name.
+func Budgets(conf *config.Config, violations []report.Violation) []report.Budget {
+	// without a resolver, there is nothing to fail
+	budgets, _ := ResolvedBudgets(conf, nil, violations)
+
+	return budgets
+}
+
+// ResolvedBudgets returns the violation budgets configured for the files with violations, as resolved by resolve,
+// along with those configured in conf, which is used for files that have no configuration of their own. Each budget
+// counts only the violations in the files configured with it, so that budgets set in nested config files, or in
+// overrides, apply only to the files they configure. Budgets configured alike for several files, like when set in a
+// config file applying to all of them, are counted as one. Budgets are returned in the same order as by Budgets,
+// with budgets of the same rule or category ordered by limit.
+func ResolvedBudgets(
+	conf *config.Config,
+	resolve func(string) (*config.Config, error),
+	violations []report.Violation,
+) ([]report.Budget, error) {
+	budgets := configuredBudgets(conf)
+	byFile := make(map[string][]report.Budget)
+
+	for _, violation := range violations {
+		file := violation.Location.File
+
+		fileBudgets, ok := byFile[file]
+		if !ok {
+			fileConf := conf
+
+			if resolve != nil {
+				resolved, err := resolve(file)
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve config for %s: %w", file, err)
+				}
+
+				if resolved != nil {
+					fileConf = resolved
+				}
+			}
+
+			fileBudgets = configuredBudgets(fileConf)
+			byFile[file] = fileBudgets
+		}
+
+		for _, budget := range fileBudgets {
+			if !budget.Covers(violation) {
+				continue
+			}
+
+			i := slices.IndexFunc(budgets, func(b report.Budget) bool {
+				b.Count = budget.Count
+
+				return b == budget
+			})
+			if i == -1 {
+				budgets = append(budgets, budget)
+				i = len(budgets) - 1
+			}
+
+			budgets[i].Count++
+		}
+	}
+
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	slices.SortStableFunc(budgets, func(a, b report.Budget) int {
+		if a.Scope != b.Scope {
+			return slices.Index(budgetScopes, a.Scope) - slices.Index(budgetScopes, b.Scope)
+		}
+
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+
+		return a.Limit - b.Limit
+	})
+
+	return budgets, nil
+}
+
+// budgetScopes in the order budgets are returned.
+var budgetScopes = []string{ //nolint:gochecknoglobals
+	report.BudgetScopeGlobal,
+	report.BudgetScopeCategory,
+	report.BudgetScopeRule,
+}
+
+// configuredBudgets returns the budgets configured in conf, with no violations counted.
+func configuredBudgets(conf *config.Config) []report.Budget {
+	if conf == nil {
+		return nil
+	}
+
+	budgets := make([]report.Budget, 0)
+
+	if conf.Defaults.Global.MaxWarnings != nil {
+		budgets = append(budgets, report.Budget{
+			Scope: report.BudgetScopeGlobal,
+			Level: config.LevelWarning,
+			Limit: *conf.Defaults.Global.MaxWarnings,
+		})
+	}
+
+	for category, defaults := range conf.Defaults.Categories {
+		if defaults.MaxWarnings != nil {
+			budgets = append(budgets, report.Budget{
+				Scope: report.BudgetScopeCategory,
+				Name:  category,
+				Level: config.LevelWarning,
+				Limit: *defaults.MaxWarnings,
+			})
+		}
+	}
+
+	for category, rulesInCategory := range conf.Rules {
+		for title, rule := range rulesInCategory {
+			if rule.MaxViolations != nil {
+				budgets = append(budgets, report.Budget{
+					Scope: report.BudgetScopeRule,
+					Name:  category + "/" + title,
+					Limit: *rule.MaxViolations,
+				})
+			}
+		}
+	}
+
+	return budgets
+}
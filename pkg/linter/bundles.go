@@ -0,0 +1,221 @@
+package linter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/opa/bundle"
+
+	rio "github.com/styrainc/regal/internal/io"
+	"github.com/styrainc/regal/pkg/config"
+)
+
+// customRulesRoot is the root that all custom rules are found under, and which the roots of custom rule
+// bundles must be within.
+const customRulesRoot = "custom/regal/rules"
+
+// customRuleBundles holds the custom rule bundles of a linter, which are loaded, and when referenced by URL,
+// downloaded, only once. This is shared between copies of a linter.
+type customRuleBundles struct {
+	once    sync.Once
+	bundles map[string]*bundle.Bundle
+	err     error
+}
+
+// IsCustomRulesBundle returns true if path refers to a bundle of custom rules, rather than to a directory
+// or file of Rego, i.e. if path is a .tar.gz bundle archive, or an HTTP(S) URL of one.
+func IsCustomRulesBundle(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || IsCustomRulesURL(path)
+}
+
+// IsCustomRulesURL returns true if path is an HTTP(S) URL of a bundle of custom rules.
+func IsCustomRulesURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// customRulesFiles returns the custom rules paths which refer to directories or files of Rego.
+func (l Linter) customRulesFiles() []string {
+	var paths []string
+
+	for _, path := range l.customRulesPaths {
+		if !IsCustomRulesBundle(path) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// customRulesBundles returns the bundles of custom rules, keyed by the path or URL they were loaded from.
+func (l Linter) customRulesBundles() (map[string]*bundle.Bundle, error) {
+	if l.customBundles == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	l.customBundles.once.Do(func() {
+		l.customBundles.bundles, l.customBundles.err = l.loadCustomRulesBundles(context.Background())
+	})
+
+	return l.customBundles.bundles, l.customBundles.err
+}
+
+func (l Linter) loadCustomRulesBundles(ctx context.Context) (map[string]*bundle.Bundle, error) {
+	bundles := make(map[string]*bundle.Bundle)
+
+	for _, path := range l.customRulesPaths {
+		if !IsCustomRulesBundle(path) {
+			continue
+		}
+
+		file := path
+
+		if IsCustomRulesURL(path) {
+			cacheDir := l.customRulesCacheDir
+			if cacheDir == "" {
+				cacheDir = filepath.Join(config.GlobalDir(), "cache", "bundles")
+			}
+
+			var err error
+			if file, err = fetchBundle(ctx, path, cacheDir); err != nil {
+				return nil, fmt.Errorf("failed to download custom rules bundle from %s: %w", path, err)
+			}
+		}
+
+		b, err := readCustomRulesBundle(file, l.customRulesVerify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load custom rules bundle %s: %w", path, err)
+		}
+
+		bundles[path] = b
+	}
+
+	return bundles, nil
+}
+
+// readCustomRulesBundle reads the bundle archive at path, verifying its signature if a verification config is
+// provided. Any roots of the bundle must be within custom/regal/rules, which is also the root of bundles not
+// declaring any. As with other custom rules, tests are excluded.
+func readCustomRulesBundle(path string, verification *bundle.VerificationConfig) (*bundle.Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	b, err := bundle.NewReader(f).
+		WithBundleVerificationConfig(verification).
+		WithSkipBundleVerification(verification == nil).
+		Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if b.Manifest.Roots == nil {
+		b.Manifest.Roots = &[]string{customRulesRoot}
+	}
+
+	for _, root := range *b.Manifest.Roots {
+		if root != customRulesRoot && !strings.HasPrefix(root, customRulesRoot+"/") {
+			return nil, fmt.Errorf("bundle root %q is not within %s", root, customRulesRoot)
+		}
+	}
+
+	modules := make([]bundle.ModuleFile, 0, len(b.Modules))
+
+	for _, mf := range b.Modules {
+		if !strings.HasSuffix(mf.Path, "_test.rego") {
+			modules = append(modules, mf)
+		}
+	}
+
+	b.Modules = modules
+
+	return &b, nil
+}
+
+// fetchBundle downloads the bundle at url to dir, returning the path of the file downloaded. If a copy of the
+// bundle was downloaded before, its ETag is sent along with the request, and the copy used if the server responds
+// that the bundle has not been modified since.
+func fetchBundle(ctx context.Context, url, dir string) (string, error) {
+	h := sha256.Sum256([]byte(url))
+	path := filepath.Join(dir, hex.EncodeToString(h[:])+".tar.gz")
+	etagPath := path + ".etag"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if etag, err := os.ReadFile(etagPath); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return path, nil
+	case http.StatusOK:
+	default:
+		return "", fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create bundle cache directory: %w", err)
+	}
+
+	// the bundle is first written to a temporary file, so that a failed download never replaces a previous one
+	tmp, err := os.CreateTemp(dir, "bundle-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create bundle file: %w", err)
+	}
+
+	if _, err = io.Copy(tmp, resp.Body); err != nil {
+		rio.CloseFileIgnore(tmp)
+		_ = os.Remove(tmp.Name())
+
+		return "", fmt.Errorf("failed to write bundle file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return "", fmt.Errorf("failed to write bundle file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return "", fmt.Errorf("failed to write bundle file: %w", err)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		err = os.WriteFile(etagPath, []byte(etag), 0o600)
+	} else {
+		err = os.Remove(etagPath)
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to store bundle ETag: %w", err)
+	}
+
+	return path, nil
+}
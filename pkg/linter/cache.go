@@ -0,0 +1,544 @@
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/open-policy-agent/opa/bundle"
+
+	rio "github.com/styrainc/regal/internal/io"
+	"github.com/styrainc/regal/internal/util"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+	"github.com/styrainc/regal/pkg/version"
+)
+
+// Cache is a persistent, on-disk cache of per-file lint results. Entries are keyed by the name and
+// content of the linted file, as well as a fingerprint of everything else that may affect the result
+// of linting it: the Regal version, the rules (both bundled and custom), the merged configuration, any
+// schemas configured and any enable/disable overrides. Aggregate rules are never cached, but the aggregate
+// data collected for each file is, so that the aggregate phase can run over cached and fresh data alike.
+// Neither are the Go rules checking all files together, like compile-error, which are run over cached and
+// fresh files alike. Entries not used for cacheMaxAge are removed from the cache directory whenever new
+// entries are stored.
+type Cache struct {
+	dir string
+
+	// used only for in-memory caches, where entries are keyed by file name, so that
+	// an entry is replaced when the file changes
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	key   string
+	entry *cacheEntry
+}
+
+// cacheMaxAge is how long an entry may go unused before it is removed from the cache directory.
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// cacheEntry is what's stored on disk for each linted file.
+type cacheEntry struct {
+	Violations       []report.Violation            `json:"violations"`
+	Notices          []report.Notice               `json:"notices,omitempty"`
+	Aggregates       map[string][]report.Aggregate `json:"aggregates,omitempty"`
+	IgnoreDirectives map[string][]string           `json:"ignore_directives,omitempty"`
+	Directives       []report.IgnoreDirective      `json:"directives,omitempty"`
+	Suppressed       []report.Violation            `json:"suppressed,omitempty"`
+	// Expires is the date of the first ignore directive in the file to expire, after which the entry is stale
+	Expires string `json:"expires,omitempty"`
+}
+
+// stale returns true if an ignore directive in the file has expired since the entry was stored. Like in the
+// Rego policy, a directive applies until the end of the day named, i.e. it expires at 00:00 UTC the day after.
+func (e *cacheEntry) stale(now time.Time) bool {
+	if e.Expires == "" {
+		return false
+	}
+
+	expires, err := time.Parse(time.DateOnly, e.Expires)
+
+	return err == nil && now.After(expires.AddDate(0, 0, 1))
+}
+
+// directivesExpiry returns the first date on which any of directives not yet expired expires, if any.
+func directivesExpiry(directives []report.IgnoreDirective) string {
+	expires := ""
+
+	for _, directive := range directives {
+		if directive.Expired || directive.Until == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.DateOnly, directive.Until); err != nil {
+			continue
+		}
+
+		// dates in YYYY-MM-DD format sort lexically
+		if expires == "" || directive.Until < expires {
+			expires = directive.Until
+		}
+	}
+
+	return expires
+}
+
+// NewCache creates a new Cache storing its entries in dir. The directory is created on first write
+// if it does not already exist.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// NewInMemoryCache creates a new Cache keeping the latest entry of each file in memory for the lifetime
+// of the cache. This is useful for long-lived linters, where only files that have changed need to be linted again.
+func NewInMemoryCache() *Cache {
+	return &Cache{entries: make(map[string]memoryEntry)}
+}
+
+// Dir returns the directory where the cache entries are stored, or an empty string for in-memory caches.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// get returns the entry for the file name stored under key, if any.
+func (c *Cache) get(name, key string) (*cacheEntry, bool) {
+	if c.entries != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if e, ok := c.entries[name]; ok && e.key == key {
+			return e.entry, true
+		}
+
+		return nil, false
+	}
+
+	path := c.entryPath(key)
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(bs, &entry); err != nil {
+		// corrupt or incompatible entry, treat as a miss and let it be overwritten
+		return nil, false
+	}
+
+	// the modification time tells when the entry was last used, for prune to tell which entries to remove
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return &entry, true
+}
+
+// put stores entry for the file name under key, replacing any entry stored for the same file in memory.
+func (c *Cache) put(name, key string, entry *cacheEntry) error {
+	if c.entries != nil {
+		c.mu.Lock()
+		c.entries[name] = memoryEntry{key: key, entry: entry}
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.dir, err)
+	}
+
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	// write to a temporary file first and rename it, so that concurrent
+	// runs never read a partially written entry
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	if _, err = tmp.Write(bs); err != nil {
+		rio.CloseFileIgnore(tmp)
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), c.entryPath(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// retain removes the entries of files other than names from an in-memory cache, as those files have since been
+// removed, or are no longer linted. Entries on disk are instead removed by prune.
+func (c *Cache) retain(names map[string]string) {
+	if c.entries == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name := range c.entries {
+		if _, ok := names[name]; !ok {
+			delete(c.entries, name)
+		}
+	}
+}
+
+// prune removes the entries, and any temporary files left behind by interrupted writes, not used for maxAge.
+func (c *Cache) prune(maxAge time.Duration) error {
+	if c.entries != nil {
+		return nil
+	}
+
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read cache directory %s: %w", c.dir, err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+
+	for _, de := range dirEntries {
+		if de.IsDir() || (filepath.Ext(de.Name()) != ".json" && filepath.Ext(de.Name()) != ".tmp") {
+			continue
+		}
+
+		info, err := de.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.dir, de.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// fileKey returns the cache key for a single file, given the fingerprint of the linter.
+func fileKey(fingerprint, name, content string) string {
+	h := sha256.New()
+
+	writeHashed(h, fingerprint, name, content)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheFingerprint returns a hash of everything, except the file itself, that may affect
+// the result of linting a file.
+func (l Linter) cacheFingerprint(conf *config.Config) (string, error) {
+	h := sha256.New()
+
+	writeHashed(h, version.Version, l.rootDir)
+
+	confJSON, err := json.Marshal(config.ToMap(*conf))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	paramsJSON, err := json.Marshal(l.paramsToRulesConfig())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal params: %w", err)
+	}
+
+	h.Write(confJSON)
+	h.Write(paramsJSON)
+
+	// the schemas used by the schema-mismatch rule are read from files not otherwise part of the config
+	if conf.Schemas != nil {
+		schemasJSON, err := json.Marshal(conf.Schemas)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal schemas config: %w", err)
+		}
+
+		h.Write(schemasJSON)
+
+		if conf.Schemas.Directory != "" {
+			if err := hashPath(h, conf.Schemas.Directory); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, b := range l.ruleBundles {
+		if err := hashBundle(h, b); err != nil {
+			return "", err
+		}
+	}
+
+	for _, path := range l.customRulesFiles() {
+		if err := hashPath(h, path); err != nil {
+			return "", err
+		}
+	}
+
+	customBundles, err := l.customRulesBundles()
+	if err != nil {
+		return "", err
+	}
+
+	bundlePaths := util.Keys(customBundles)
+	slices.Sort(bundlePaths)
+
+	for _, path := range bundlePaths {
+		writeHashed(h, path)
+
+		if err := hashBundle(h, customBundles[path]); err != nil {
+			return "", err
+		}
+	}
+
+	if l.customRuleFS != nil && l.customRuleFSRootPath != "" {
+		files, err := loadModulesFromCustomRuleFS(l.customRuleFS, l.customRuleFSRootPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to load custom rules from FS: %w", err)
+		}
+
+		names := util.Keys(files)
+		slices.Sort(names)
+
+		for _, name := range names {
+			writeHashed(h, name, files[name])
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBundle(h hash.Hash, b *bundle.Bundle) error {
+	for _, mf := range b.Modules {
+		writeHashed(h, mf.Path, string(mf.Raw))
+	}
+
+	bs, err := json.Marshal(b.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle data: %w", err)
+	}
+
+	h.Write(bs)
+
+	return nil
+}
+
+// hashPath hashes the name and contents of all files found at path, in lexical order.
+func hashPath(h hash.Hash, path string) error {
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		bs, err := os.ReadFile(p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		writeHashed(h, p, string(bs))
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to hash files at %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// writeHashed writes each value to h, prefixed by its length to avoid ambiguity between
+// e.g. ("ab", "c") and ("a", "bc").
+func writeHashed(h hash.Hash, values ...string) {
+	for _, v := range values {
+		fmt.Fprintf(h, "%d:", len(v))
+		h.Write([]byte(v))
+	}
+}
+
+// fingerprintFunc returns a function returning the cache fingerprint for a file, which is computed from the
+// combined config conf, unless the configuration of the file differs. Fingerprints are computed once for each
+// configuration.
+func (l Linter) fingerprintFunc(conf *config.Config) func(string) (string, error) {
+	fingerprints := make(map[string]string)
+
+	return func(name string) (string, error) {
+		id := l.fileConfigID(name)
+
+		if fingerprint, ok := fingerprints[id]; ok {
+			return fingerprint, nil
+		}
+
+		fileConf := conf
+		if id != "" {
+			fileConf = l.fileConfigs.configs[id]
+		}
+
+		fingerprint, err := l.cacheFingerprint(fileConf)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute cache fingerprint: %w", err)
+		}
+
+		fingerprints[id] = fingerprint
+
+		return fingerprint, nil
+	}
+}
+
+// lookupCache looks up each of names in the cache, recording the key used for each name in keys,
+// and any entries found in hits. The names not found in the cache, or for which the entry found is stale,
+// are returned, in their original order.
+func (l Linter) lookupCache(
+	fingerprint func(string) (string, error),
+	names []string,
+	content func(string) (string, error),
+	keys map[string]string,
+	hits map[string]*cacheEntry,
+) ([]string, error) {
+	misses := make([]string, 0, len(names))
+	now := time.Now()
+
+	for _, name := range names {
+		c, err := content(name)
+		if err != nil {
+			return nil, err
+		}
+
+		fp, err := fingerprint(name)
+		if err != nil {
+			return nil, err
+		}
+
+		key := fileKey(fp, name, c)
+		keys[name] = key
+
+		if entry, ok := l.cache.get(name, key); ok && !entry.stale(now) {
+			hits[name] = entry
+
+			continue
+		}
+
+		misses = append(misses, name)
+	}
+
+	return misses, nil
+}
+
+// storeCacheEntries stores the fresh results from the Rego rules in the cache, together with
+// any violations reported, or suppressed by ignore directives, by the Go rules for the same file.
+func storeCacheEntries(
+	cache *Cache,
+	keys map[string]string,
+	fresh map[string]*cacheEntry,
+	goViolations []report.Violation,
+	goSuppressed []report.Violation,
+) error {
+	for _, violation := range goViolations {
+		if entry, ok := fresh[violation.Location.File]; ok {
+			entry.Violations = append(entry.Violations, violation)
+		}
+	}
+
+	for _, violation := range goSuppressed {
+		if entry, ok := fresh[violation.Location.File]; ok {
+			entry.Suppressed = append(entry.Suppressed, violation)
+		}
+	}
+
+	for name, entry := range fresh {
+		entry.Expires = directivesExpiry(entry.Directives)
+
+		if err := cache.put(name, keys[name], entry); err != nil {
+			return fmt.Errorf("failed to store lint result for %s in cache: %w", name, err)
+		}
+	}
+
+	if len(fresh) > 0 {
+		if err := cache.prune(cacheMaxAge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addCachedResults adds the results found in the cache to the report, as if they were just produced
+// by evaluating the rules.
+func addCachedResults(rep *report.Report, hits map[string]*cacheEntry) {
+	if rep.Aggregates == nil {
+		rep.Aggregates = make(map[string][]report.Aggregate)
+	}
+
+	if rep.IgnoreDirectives == nil {
+		rep.IgnoreDirectives = make(map[string]map[string][]string)
+	}
+
+	if rep.Directives == nil {
+		rep.Directives = make(map[string][]report.IgnoreDirective)
+	}
+
+	names := util.Keys(hits)
+	slices.Sort(names)
+
+	for _, name := range names {
+		entry := hits[name]
+
+		rep.Violations = append(rep.Violations, entry.Violations...)
+		rep.Notices = append(rep.Notices, entry.Notices...)
+		rep.Suppressed = append(rep.Suppressed, entry.Suppressed...)
+
+		for k := range entry.Aggregates {
+			rep.Aggregates[k] = append(rep.Aggregates[k], entry.Aggregates[k]...)
+		}
+
+		if entry.IgnoreDirectives != nil {
+			rep.IgnoreDirectives[name] = entry.IgnoreDirectives
+		}
+
+		if entry.Directives != nil {
+			rep.Directives[name] = entry.Directives
+		}
+	}
+}
+
+func readFileContent(name string) (string, error) {
+	bs, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", name, err)
+	}
+
+	return string(bs), nil
+}
@@ -0,0 +1,119 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/styrainc/regal/pkg/config"
+)
+
+// fileConfigs keeps track of the configuration of files for which the config resolver of the linter returns
+// configuration differing from the combined config of the linter. Each distinct configuration is identified by
+// an ID, under which it is provided to the Rego rules, along with the ID of the configuration of each file.
+type fileConfigs struct {
+	// baseJSON is the combined config of the linter, as JSON
+	baseJSON string
+	// ids are the IDs of the configuration of files, by file name
+	ids map[string]string
+	// configs are the configurations, merged with the provided configuration, by ID
+	configs map[string]*config.Config
+	// byJSON are the IDs of the configurations, by JSON
+	byJSON map[string]string
+	// resolved are the IDs of the configurations returned by the resolver, or an empty string for
+	// those equal to the combined config of the linter
+	resolved map[*config.Config]string
+}
+
+func newFileConfigs(conf *config.Config) (*fileConfigs, error) {
+	bs, err := json.Marshal(config.ToMap(*conf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return &fileConfigs{
+		baseJSON: string(bs),
+		ids:      make(map[string]string),
+		configs:  make(map[string]*config.Config),
+		byJSON:   make(map[string]string),
+		resolved: make(map[*config.Config]string),
+	}, nil
+}
+
+// resolveFileConfigs resolves the configuration of each of the files with names, using the config resolver.
+func (l Linter) resolveFileConfigs(names []string) error {
+	if l.configResolver == nil {
+		return nil
+	}
+
+	regalBundle, err := l.getBundleByName("regal")
+	if err != nil {
+		return fmt.Errorf("failed to get regal bundle: %w", err)
+	}
+
+	fc := l.fileConfigs
+
+	for _, name := range names {
+		userConfig, err := l.configResolver(name)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config for %s: %w", name, err)
+		}
+
+		if userConfig == nil {
+			continue
+		}
+
+		id, ok := fc.resolved[userConfig]
+		if !ok {
+			merged, err := config.LoadConfigWithDefaultsFromBundle(regalBundle, userConfig)
+			if err != nil {
+				return fmt.Errorf("failed to merge config for %s: %w", name, err)
+			}
+
+			bs, err := json.Marshal(config.ToMap(merged))
+			if err != nil {
+				return fmt.Errorf("failed to marshal config for %s: %w", name, err)
+			}
+
+			if id, ok = fc.byJSON[string(bs)]; !ok && string(bs) != fc.baseJSON {
+				id = strconv.Itoa(len(fc.configs))
+				fc.byJSON[string(bs)] = id
+				fc.configs[id] = &merged
+			}
+
+			fc.resolved[userConfig] = id
+		}
+
+		if id != "" {
+			fc.ids[name] = id
+		}
+	}
+
+	return nil
+}
+
+// fileConfigID returns the ID of the configuration of the file with name, or an empty string if the file is
+// linted using the combined config of the linter.
+func (l Linter) fileConfigID(name string) string {
+	if l.fileConfigs == nil {
+		return ""
+	}
+
+	return l.fileConfigs.ids[name]
+}
+
+// configData returns the configurations of files differing from the combined config, and the ID of the
+// configuration of each of those files, for use by the Rego rules.
+func (fc *fileConfigs) configData() (map[string]any, map[string]any) {
+	configs := make(map[string]any, len(fc.configs))
+	for id, conf := range fc.configs {
+		configs[id] = config.ToMap(*conf)
+	}
+
+	ids := make(map[string]any, len(fc.ids))
+	for name, id := range fc.ids {
+		ids[name] = id
+	}
+
+	return configs, ids
+}
@@ -2,13 +2,16 @@ package linter
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
+	"maps"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -38,12 +41,16 @@ import (
 type Linter struct {
 	inputPaths           []string
 	inputModules         *rules.Input
+	contextPaths         []string
 	rootDir              string
 	ruleBundles          []*bundle.Bundle
 	userConfig           *config.Config
 	combinedCfg          *config.Config
 	dataBundle           *bundle.Bundle
 	customRulesPaths     []string
+	customBundles        *customRuleBundles
+	customRulesVerify    *bundle.VerificationConfig
+	customRulesCacheDir  string
 	customRuleFS         fs.FS
 	customRuleFSRootPath string
 	debugMode            bool
@@ -57,6 +64,17 @@ type Linter struct {
 	ignoreFiles          []string
 	metrics              metrics.Metrics
 	profiling            bool
+	cache                *Cache
+	prepared             *preparedQueries
+	configResolver       func(string) (*config.Config, error)
+	fileConfigs          *fileConfigs
+}
+
+// preparedQueries holds queries prepared for evaluation, keyed by the query and the state of the linter
+// at the time of preparation. This is shared between copies of a linter.
+type preparedQueries struct {
+	mu      sync.Mutex
+	queries map[string]*rego.PreparedEvalQuery
 }
 
 //nolint:gochecknoglobals
@@ -65,10 +83,33 @@ var (
 	lintQuery = ast.MustParseBody(`lint := {
 		"violations": data.regal.main.lint.violations,
 		"notices": data.regal.main.lint.notices,
+		"ignore_directives": data.regal.main.lint.ignore_directives,
+		"directives": data.regal.main.lint.directives,
+		"suppressed": data.regal.main.lint.suppressed,
 	}`)
 	// More than one file provided as input.
-	lintAndCollectQuery     = ast.MustParseBody("lint := data.regal.main.lint")
+	lintAndCollectQuery = ast.MustParseBody("lint := data.regal.main.lint")
+	// Files not linted, but providing context for aggregate rules.
+	collectQuery = ast.MustParseBody(`lint := {
+		"aggregates": data.regal.main.lint.aggregates,
+		"ignore_directives": data.regal.main.lint.ignore_directives,
+	}`)
 	lintWithAggregatesQuery = ast.MustParseBody("lint_aggregate := data.regal.main.lint_aggregate")
+
+	knownRulesQuery = ast.MustParseBody(`rules := {[cat, title] |
+	some cat, title
+	data.regal.rules[cat][title]
+} | {[cat, title] |
+	some cat, title
+	data.custom.regal.rules[cat][title]
+}`)
+	aggregateRulesQuery = ast.MustParseBody(`rules := {[cat, title] |
+	some cat, title
+	data.regal.rules[cat][title].aggregate_report
+} | {[cat, title] |
+	some cat, title
+	data.custom.regal.rules[cat][title].aggregate_report
+}`)
 )
 
 // NewLinter creates a new Regal linter.
@@ -108,9 +149,29 @@ func (l Linter) WithAddedBundle(b bundle.Bundle) Linter {
 	return l
 }
 
-// WithCustomRules adds custom rules for evaluation, from the Rego (and data) files provided at paths.
+// WithCustomRules adds custom rules for evaluation, from the Rego (and data) files provided at paths. Paths may
+// also be .tar.gz bundle archives, or HTTP(S) URLs of such, with roots within custom/regal/rules.
 func (l Linter) WithCustomRules(paths []string) Linter {
 	l.customRulesPaths = paths
+	l.customBundles = &customRuleBundles{}
+
+	return l
+}
+
+// WithCustomRulesVerification sets the config used to verify the signatures of custom rule bundles. Without it,
+// bundles are not verified.
+func (l Linter) WithCustomRulesVerification(verification *bundle.VerificationConfig) Linter {
+	l.customRulesVerify = verification
+	l.customBundles = &customRuleBundles{}
+
+	return l
+}
+
+// WithCustomRulesCacheDir sets the directory where custom rule bundles referenced by URL are cached between runs.
+// The default is the cache/bundles directory of the user-wide Regal config directory.
+func (l Linter) WithCustomRulesCacheDir(dir string) Linter {
+	l.customRulesCacheDir = dir
+	l.customBundles = &customRuleBundles{}
 
 	return l
 }
@@ -131,6 +192,15 @@ func (l Linter) WithDebugMode(debugMode bool) Linter {
 	return l
 }
 
+// WithAggregateContextPaths sets paths of files that are not linted themselves, but which aggregate rules consider
+// along with the files linted, like when only some files of a project are linted. Violations of aggregate rules
+// are reported only for the files linted.
+func (l Linter) WithAggregateContextPaths(paths []string) Linter {
+	l.contextPaths = paths
+
+	return l
+}
+
 // WithUserConfig provides config overrides set by the user.
 func (l Linter) WithUserConfig(cfg config.Config) Linter {
 	l.userConfig = &cfg
@@ -138,6 +208,16 @@ func (l Linter) WithUserConfig(cfg config.Config) Linter {
 	return l
 }
 
+// WithConfigResolver sets a function returning the user configuration of each file linted, for when the
+// configuration may differ between files, like when config files are found in .regal directories nearer to
+// some files, or overrides apply to them. Files for which the function returns nil are linted using the user
+// config provided via WithUserConfig.
+func (l Linter) WithConfigResolver(resolve func(filename string) (*config.Config, error)) Linter {
+	l.configResolver = resolve
+
+	return l
+}
+
 // WithDisabledRules disables provided rules. This overrides configuration provided in file.
 func (l Linter) WithDisabledRules(disable ...string) Linter {
 	l.disable = disable
@@ -207,6 +287,29 @@ func (l Linter) WithProfiling(enabled bool) Linter {
 	return l
 }
 
+// WithCache enables caching of per-file lint results in the provided cache. Files whose content,
+// and whose linter configuration, is unchanged since they were last linted are not re-evaluated.
+// Note that caching is disabled when profiling is enabled, as the profile would otherwise be incomplete.
+func (l Linter) WithCache(cache *Cache) Linter {
+	l.cache = cache
+
+	return l
+}
+
+// WithQueryReuse makes the linter keep the queries it prepares for evaluation, and reuse them in later
+// calls to Lint, rather than preparing them again. This is useful for long-lived linters, like when
+// watching files for changes. Note that custom rules are loaded when a query is prepared, so changes
+// to custom rules on disk won't be seen by a linter reusing queries.
+func (l Linter) WithQueryReuse(enabled bool) Linter {
+	if !enabled {
+		l.prepared = nil
+	} else if l.prepared == nil {
+		l.prepared = &preparedQueries{queries: make(map[string]*rego.PreparedEvalQuery)}
+	}
+
+	return l
+}
+
 // WithRootDir sets the root directory for the linter.
 // A door directory or prefix can be use to resolve relative paths
 // referenced in the linter configuration with absolute file paths or URIs.
@@ -231,19 +334,6 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		return report.Report{}, fmt.Errorf("failed to merge config: %w", err)
 	}
 
-	l.dataBundle = &bundle.Bundle{
-		Manifest: bundle.Manifest{
-			Roots:    &[]string{"internal"},
-			Metadata: map[string]any{"name": "internal"},
-		},
-		Data: map[string]any{
-			"internal": map[string]any{
-				"combined_config": config.ToMap(*conf),
-				"capabilities":    rio.ToMap(config.CapabilitiesForThisVersion()),
-			},
-		},
-	}
-
 	ignore := conf.Ignore.Files
 
 	if len(l.ignoreFiles) > 0 {
@@ -257,7 +347,71 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		return report.Report{}, fmt.Errorf("errors encountered when reading files to lint: %w", err)
 	}
 
+	// files providing context for aggregate rules, which are not linted themselves
+	var contextFiles []string
+
+	if len(l.contextPaths) > 0 {
+		if contextFiles, err = l.aggregateContextFiles(ignore, filtered); err != nil {
+			return report.Report{}, err
+		}
+	}
+
 	l.stopTimer(regalmetrics.RegalFilterIgnoredFiles)
+
+	if l.configResolver != nil {
+		if l.fileConfigs, err = newFileConfigs(conf); err != nil {
+			return report.Report{}, err
+		}
+
+		if err = l.resolveFileConfigs(filtered); err != nil {
+			return report.Report{}, err
+		}
+
+		if l.inputModules != nil {
+			if err = l.resolveFileConfigs(l.inputModules.FileNames); err != nil {
+				return report.Report{}, err
+			}
+		}
+
+		if err = l.resolveFileConfigs(contextFiles); err != nil {
+			return report.Report{}, err
+		}
+	}
+
+	internal := map[string]any{
+		"combined_config": config.ToMap(*conf),
+		"capabilities":    rio.ToMap(config.CapabilitiesForThisVersion()),
+	}
+
+	if l.fileConfigs != nil && len(l.fileConfigs.ids) > 0 {
+		internal["configs"], internal["file_config_ids"] = l.fileConfigs.configData()
+	}
+
+	l.dataBundle = &bundle.Bundle{
+		Manifest: bundle.Manifest{
+			Roots:    &[]string{"internal"},
+			Metadata: map[string]any{"name": "internal"},
+		},
+		Data: map[string]any{"internal": internal},
+	}
+
+	cache := l.cache
+	if l.profiling {
+		cache = nil
+	}
+
+	cacheKeys := make(map[string]string)
+	cacheHits := make(map[string]*cacheEntry)
+
+	fingerprint := l.fingerprintFunc(conf)
+
+	if cache != nil {
+		filtered, err = l.lookupCache(fingerprint, filtered, readFileContent, cacheKeys, cacheHits)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when reading files to lint: %w", err)
+		}
+	}
+
 	l.startTimer(regalmetrics.RegalInputParse)
 
 	inputFromPaths, err := rules.InputFromPaths(filtered)
@@ -282,6 +436,15 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 			return report.Report{}, fmt.Errorf("failed to filter paths: %w", err)
 		}
 
+		if cache != nil {
+			filteredPaths, err = l.lookupCache(fingerprint, filteredPaths, func(name string) (string, error) {
+				return l.inputModules.FileContent[name], nil
+			}, cacheKeys, cacheHits)
+			if err != nil {
+				return report.Report{}, fmt.Errorf("failed to read cache: %w", err)
+			}
+		}
+
 		for _, filename := range filteredPaths {
 			input.FileNames = append(input.FileNames, filename)
 			input.Modules[filename] = l.inputModules.Modules[filename]
@@ -291,18 +454,103 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		l.stopTimer(regalmetrics.RegalFilterIgnoredModules)
 	}
 
-	goReport, err := l.lintWithGoRules(ctx, input)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to lint using Go rules: %w", err)
+	if cache != nil {
+		// entries of files removed since the last lint are of no further use
+		cache.retain(cacheKeys)
 	}
 
-	finalReport.Violations = append(finalReport.Violations, goReport.Violations...)
+	numFiles := len(input.FileNames) + len(cacheHits)
 
-	regoReport, err := l.lintWithRegoRules(ctx, input)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to lint using Rego rules: %w", err)
+	// aggregate rules are evaluated only when there is more than one file to consider
+	aggregates := numFiles+len(contextFiles) > 1
+
+	var goReport, regoReport report.Report
+
+	var goSuppressed []report.Violation
+
+	// with a cache, the Go rules checking all files together are run separately below, over both cached and
+	// fresh files, as their results for one file depend on the others
+	var includeGoRule func(rules.Rule) bool
+	if cache != nil {
+		includeGoRule = isFileRule
+	}
+
+	// with every file found in the cache, there is nothing left to evaluate
+	// before the aggregate phase
+	if cache == nil || len(input.FileNames) > 0 {
+		goReport, err = l.lintWithGoRules(ctx, input, includeGoRule)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("failed to lint using Go rules: %w", err)
+		}
+
+		var fresh map[string]*cacheEntry
+
+		var onResult func(string, report.Report)
+
+		if cache != nil {
+			fresh = make(map[string]*cacheEntry, len(input.FileNames))
+			onResult = func(name string, result report.Report) {
+				fresh[name] = &cacheEntry{
+					Violations:       result.Violations,
+					Notices:          result.Notices,
+					Aggregates:       result.Aggregates,
+					IgnoreDirectives: result.IgnoreDirectives[name],
+					Directives:       result.Directives[name],
+					Suppressed:       result.Suppressed,
+				}
+			}
+		}
+
+		query := lintQuery
+		if aggregates || cache != nil {
+			query = lintAndCollectQuery
+		}
+
+		regoReport, err = l.lintWithRegoRules(ctx, input, query, onResult)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("failed to lint using Rego rules: %w", err)
+		}
+
+		goReport.Violations, goSuppressed = filterIgnoredViolations(goReport.Violations, regoReport.IgnoreDirectives)
+
+		if cache != nil {
+			if err = storeCacheEntries(cache, cacheKeys, fresh, goReport.Violations, goSuppressed); err != nil {
+				return report.Report{}, err
+			}
+		}
+	}
+
+	if len(cacheHits) > 0 {
+		addCachedResults(&regoReport, cacheHits)
 	}
 
+	if cache != nil {
+		violations, suppressed, err := l.lintWithProgramRules(ctx, input, cacheHits, regoReport.IgnoreDirectives)
+		if err != nil {
+			return report.Report{}, err
+		}
+
+		goReport.Violations = append(goReport.Violations, violations...)
+		goSuppressed = append(goSuppressed, suppressed...)
+	}
+
+	if aggregates && len(contextFiles) > 0 {
+		var contextFingerprint func(string) (string, error)
+		if cache != nil {
+			contextFingerprint = fingerprint
+		}
+
+		if err = l.collectAggregateContext(ctx, contextFiles, contextFingerprint, &regoReport); err != nil {
+			return report.Report{}, fmt.Errorf("failed to collect aggregates from context files: %w", err)
+		}
+	}
+
+	if l.metrics != nil && cache != nil {
+		l.metrics.Counter(regalmetrics.RegalLintCacheHits).Add(uint64(len(cacheHits)))
+		l.metrics.Counter(regalmetrics.RegalLintCacheMisses).Add(uint64(len(input.FileNames)))
+	}
+
+	finalReport.Violations = append(finalReport.Violations, goReport.Violations...)
 	finalReport.Violations = append(finalReport.Violations, regoReport.Violations...)
 
 	rulesSkippedCounter := 0
@@ -317,17 +565,33 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		}
 	}
 
-	if len(input.FileNames) > 1 {
+	suppressed := slices.Concat(regoReport.Suppressed, goSuppressed)
+
+	if aggregates {
 		aggregateReport, err := l.lintWithRegoAggregateRules(ctx, regoReport.Aggregates, regoReport.IgnoreDirectives)
 		if err != nil {
 			return report.Report{}, fmt.Errorf("failed to lint using Rego aggregate rules: %w", err)
 		}
 
-		finalReport.Violations = append(finalReport.Violations, aggregateReport.Violations...)
+		for _, violation := range aggregateReport.Violations {
+			if !slices.Contains(contextFiles, violation.Location.File) {
+				finalReport.Violations = append(finalReport.Violations, violation)
+			}
+		}
+
+		suppressed = append(suppressed, aggregateReport.Suppressed...)
 	}
 
+	unused, err := l.lintUnusedIgnoreDirectives(ctx, conf, regoReport.Directives, suppressed, aggregates)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("failed to lint ignore directives: %w", err)
+	}
+
+	unused, _ = filterIgnoredViolations(unused, regoReport.IgnoreDirectives)
+	finalReport.Violations = append(finalReport.Violations, unused...)
+
 	finalReport.Summary = report.Summary{
-		FilesScanned:  len(input.FileNames),
+		FilesScanned:  numFiles,
 		FilesFailed:   len(finalReport.ViolationsFileCount()),
 		RulesSkipped:  rulesSkippedCounter,
 		NumViolations: len(finalReport.Violations),
@@ -348,13 +612,132 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 	return finalReport, nil
 }
 
+// filterIgnoredViolations removes violations of Go rules ignored by directives, like `# regal ignore:opa-fmt`,
+// which apply to the line of the directive and the line following it, or to the whole file when indexed by row 0.
+// The directives are collected, and applied to violations of Rego rules, by the Rego rules. The violations kept
+// are returned along with those suppressed.
+func filterIgnoredViolations(
+	violations []report.Violation,
+	directives map[string]map[string][]string,
+) ([]report.Violation, []report.Violation) {
+	filtered := make([]report.Violation, 0, len(violations))
+	suppressed := make([]report.Violation, 0)
+
+	for _, violation := range violations {
+		rows := directives[violation.Location.File]
+		row := violation.Location.Row
+
+		ignored := slices.Concat(rows["0"], rows[strconv.Itoa(row)], rows[strconv.Itoa(row+1)])
+		if slices.ContainsFunc(ignored, func(directive string) bool {
+			return rules.IgnoresViolation(directive, violation)
+		}) {
+			suppressed = append(suppressed, violation)
+
+			continue
+		}
+
+		filtered = append(filtered, violation)
+	}
+
+	return filtered, suppressed
+}
+
+// lintUnusedIgnoreDirectives reports the ignore directives suppressing no violations in files where the
+// unused-ignore-directive rule is enabled. Unless aggregate rules were evaluated, which they are only when
+// linting more than one file, directives referencing them are not reported, as they can't be told unused.
+func (l Linter) lintUnusedIgnoreDirectives(
+	ctx context.Context,
+	conf *config.Config,
+	directives map[string][]report.IgnoreDirective,
+	suppressed []report.Violation,
+	aggregates bool,
+) ([]report.Violation, error) {
+	violations := make([]report.Violation, 0)
+
+	var known config.KnownRules
+
+	var aggregateRules map[string]bool
+
+	// the unused-ignore-directive rule enabled by each configuration, if any
+	enabledRules := make(map[string]*rules.UnusedIgnoreDirectiveRule)
+
+	names := util.Keys(directives)
+	slices.Sort(names)
+
+	for _, name := range names {
+		if len(directives[name]) == 0 {
+			continue
+		}
+
+		id := l.fileConfigID(name)
+
+		fileConf := conf
+		if id != "" {
+			fileConf = l.fileConfigs.configs[id]
+		}
+
+		rule, ok := enabledRules[id]
+		if !ok {
+			goRules, err := l.enabledGoRules(fileConf)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get configured Go rules: %w", err)
+			}
+
+			for _, goRule := range goRules {
+				if r, ok := goRule.(*rules.UnusedIgnoreDirectiveRule); ok {
+					rule = r
+				}
+			}
+
+			enabledRules[id] = rule
+		}
+
+		if rule == nil {
+			continue
+		}
+
+		if known == nil {
+			var err error
+			if known, err = l.KnownRules(ctx); err != nil {
+				return nil, fmt.Errorf("failed to get known rules: %w", err)
+			}
+
+			if !aggregates {
+				if aggregateRules, err = l.aggregateRules(ctx); err != nil {
+					return nil, fmt.Errorf("failed to get aggregate rules: %w", err)
+				}
+			}
+		}
+
+		enabled := func(category, title string) bool {
+			if !aggregates && aggregateRules[category+"/"+title] {
+				return false
+			}
+
+			return l.ruleEnabled(fileConf, category, title)
+		}
+
+		fileSuppressed := make([]report.Violation, 0)
+
+		for _, violation := range suppressed {
+			if violation.Location.File == name {
+				fileSuppressed = append(fileSuppressed, violation)
+			}
+		}
+
+		violations = append(violations, rule.Report(name, directives[name], fileSuppressed, known, enabled)...)
+	}
+
+	return violations, nil
+}
+
 // DetermineEnabledRules returns the list of rules that are enabled based on the supplied configuration.
 // This makes use of the Rego and Go rule settings to produce a single list of the rules that are to be run
 // on this linter instance.
 func (l Linter) DetermineEnabledRules(ctx context.Context) ([]string, error) {
 	enabledRules := make([]string, 0)
 
-	goRules, err := l.enabledGoRules()
+	goRules, err := l.enabledGoRules(nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get enabled Go rules: %w", err)
 	}
@@ -401,32 +784,157 @@ data.regal.config.for_rule(cat, rule).level != "ignore"
 	return enabledRules, nil
 }
 
-func (l Linter) lintWithGoRules(ctx context.Context, input rules.Input) (report.Report, error) {
+// lintWithGoRules runs the enabled Go rules for which include returns true, or all of them if include is nil.
+func (l Linter) lintWithGoRules(
+	ctx context.Context,
+	input rules.Input,
+	include func(rules.Rule) bool,
+) (report.Report, error) {
 	l.startTimer(regalmetrics.RegalLintGo)
 	defer l.stopTimer(regalmetrics.RegalLintGo)
 
-	goRules, err := l.enabledGoRules()
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to get configured Go rules: %w", err)
+	// the IDs of the configurations of the files linted, where the empty string is the combined config
+	ids := make([]string, 0, 1)
+
+	if slices.ContainsFunc(input.FileNames, func(name string) bool { return l.fileConfigID(name) == "" }) {
+		ids = append(ids, "")
+	}
+
+	if l.fileConfigs != nil {
+		configIDs := util.Keys(l.fileConfigs.configs)
+		slices.Sort(configIDs)
+
+		ids = append(ids, configIDs...)
 	}
 
 	aggregate := report.Report{}
 
+	// as some rules need all modules, like those compiling them, the rules enabled by each configuration are
+	// run with all modules as input, but only violations in files with that configuration are kept
+	for _, id := range ids {
+		var conf *config.Config
+		if id != "" {
+			conf = l.fileConfigs.configs[id]
+		}
+
+		goRules, err := l.enabledGoRules(conf)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("failed to get configured Go rules: %w", err)
+		}
+
+		if include != nil {
+			goRules = slices.DeleteFunc(goRules, func(rule rules.Rule) bool { return !include(rule) })
+		}
+
+		violations, err := runGoRules(ctx, goRules, input)
+		if err != nil {
+			return report.Report{}, err
+		}
+
+		for _, violation := range violations {
+			if len(ids) == 1 || l.fileConfigID(violation.Location.File) == id {
+				aggregate.Violations = append(aggregate.Violations, violation)
+			}
+		}
+	}
+
+	return aggregate, nil
+}
+
+// lintWithProgramRules runs the Go rules checking all files together over the fresh input along with the files
+// found in the cache, returning the violations not ignored by any of directives, and those suppressed by them.
+func (l Linter) lintWithProgramRules(
+	ctx context.Context,
+	input rules.Input,
+	hits map[string]*cacheEntry,
+	directives map[string]map[string][]string,
+) ([]report.Violation, []report.Violation, error) {
+	all := rules.Input{
+		FileNames:   slices.Clone(input.FileNames),
+		FileContent: maps.Clone(input.FileContent),
+		Modules:     maps.Clone(input.Modules),
+	}
+
+	names := util.Keys(hits)
+	slices.Sort(names)
+
+	paths := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if l.inputModules != nil {
+			if module, ok := l.inputModules.Modules[name]; ok {
+				all.FileNames = append(all.FileNames, name)
+				all.FileContent[name] = l.inputModules.FileContent[name]
+				all.Modules[name] = module
+
+				continue
+			}
+		}
+
+		paths = append(paths, name)
+	}
+
+	if len(paths) > 0 {
+		l.startTimer(regalmetrics.RegalInputParse)
+
+		cached, err := rules.InputFromPaths(paths)
+		if err != nil {
+			return nil, nil, fmt.Errorf("errors encountered when reading files to lint: %w", err)
+		}
+
+		l.stopTimer(regalmetrics.RegalInputParse)
+
+		for _, name := range cached.FileNames {
+			all.FileNames = append(all.FileNames, name)
+			all.FileContent[name] = cached.FileContent[name]
+			all.Modules[name] = cached.Modules[name]
+		}
+	}
+
+	if len(all.FileNames) == 0 {
+		return nil, nil, nil
+	}
+
+	slices.Sort(all.FileNames)
+
+	programReport, err := l.lintWithGoRules(ctx, all, isProgramRule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lint using Go rules: %w", err)
+	}
+
+	violations, suppressed := filterIgnoredViolations(programReport.Violations, directives)
+
+	return violations, suppressed, nil
+}
+
+func isProgramRule(rule rules.Rule) bool {
+	_, ok := rule.(rules.ProgramRule)
+
+	return ok
+}
+
+func isFileRule(rule rules.Rule) bool {
+	return !isProgramRule(rule)
+}
+
+func runGoRules(ctx context.Context, goRules []rules.Rule, input rules.Input) ([]report.Violation, error) {
+	violations := make([]report.Violation, 0)
+
 	for _, rule := range goRules {
 		inp, err := inputForRule(input, rule)
 		if err != nil {
-			return report.Report{}, fmt.Errorf("error encountered while filtering input files: %w", err)
+			return nil, fmt.Errorf("error encountered while filtering input files: %w", err)
 		}
 
 		result, err := rule.Run(ctx, inp)
 		if err != nil {
-			return report.Report{}, fmt.Errorf("error encountered in Go rule evaluation: %w", err)
+			return nil, fmt.Errorf("error encountered in Go rule evaluation: %w", err)
 		}
 
-		aggregate.Violations = append(aggregate.Violations, result.Violations...)
+		violations = append(violations, result.Violations...)
 	}
 
-	return aggregate, err
+	return violations, nil
 }
 
 func inputForRule(input rules.Input, rule rules.Rule) (rules.Input, error) {
@@ -583,8 +1091,20 @@ func (l Linter) prepareRegoArgs(query ast.Body) ([]func(*rego.Rego), error) {
 		regoArgs = append(regoArgs, rego.ParsedBundle("internal", l.dataBundle))
 	}
 
-	if l.customRulesPaths != nil {
-		regoArgs = append(regoArgs, rego.Load(l.customRulesPaths, rio.ExcludeTestFilter()))
+	if files := l.customRulesFiles(); files != nil {
+		regoArgs = append(regoArgs, rego.Load(files, rio.ExcludeTestFilter()))
+	}
+
+	customBundles, err := l.customRulesBundles()
+	if err != nil {
+		return nil, err
+	}
+
+	bundlePaths := util.Keys(customBundles)
+	slices.Sort(bundlePaths)
+
+	for _, path := range bundlePaths {
+		regoArgs = append(regoArgs, rego.ParsedBundle(path, customBundles[path]))
 	}
 
 	if l.customRuleFS != nil && l.customRuleFSRootPath != "" {
@@ -612,6 +1132,65 @@ func (l Linter) prepareRegoArgs(query ast.Body) ([]func(*rego.Rego), error) {
 	return regoArgs, nil
 }
 
+// prepareQuery prepares query for evaluation, or returns a previously prepared query if the linter
+// reuses queries, and neither the query nor the configuration of the linter has changed since.
+func (l Linter) prepareQuery(ctx context.Context, query ast.Body) (*rego.PreparedEvalQuery, error) {
+	var key string
+
+	if l.prepared != nil {
+		var err error
+		if key, err = l.preparedQueryKey(query); err != nil {
+			return nil, err
+		}
+
+		l.prepared.mu.Lock()
+		defer l.prepared.mu.Unlock()
+
+		if pq, ok := l.prepared.queries[key]; ok {
+			return pq, nil
+		}
+	}
+
+	regoArgs, err := l.prepareRegoArgs(query)
+	if err != nil {
+		return nil, err
+	}
+
+	pq, err := rego.New(regoArgs...).PrepareForEval(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if l.prepared != nil {
+		l.prepared.queries[key] = &pq
+	}
+
+	return &pq, nil
+}
+
+func (l Linter) preparedQueryKey(query ast.Body) (string, error) {
+	var data any
+	if l.dataBundle != nil {
+		data = l.dataBundle.Data
+	}
+
+	bs, err := json.Marshal([]any{
+		query.String(),
+		data,
+		l.paramsToRulesConfig(),
+		l.customRulesPaths,
+		l.customRulesVerify,
+		l.customRuleFSRootPath,
+		l.debugMode,
+		l.printHook != nil,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create key for prepared query: %w", err)
+	}
+
+	return string(bs), nil
+}
+
 func loadModulesFromCustomRuleFS(customRuleFS fs.FS, rootPath string) (map[string]string, error) {
 	files := make(map[string]string)
 	filter := rio.ExcludeTestFilter()
@@ -656,26 +1235,22 @@ func loadModulesFromCustomRuleFS(customRuleFS fs.FS, rootPath string) (map[strin
 	return files, nil
 }
 
-func (l Linter) lintWithRegoRules(ctx context.Context, input rules.Input) (report.Report, error) {
+// lintWithRegoRules evaluates query for each file in input, where lintAndCollectQuery also collects aggregates
+// and ignore directives for use in the aggregate phase, which lintQuery doesn't. If onResult is provided, it is
+// called with the result of each file as soon as it is available.
+func (l Linter) lintWithRegoRules(
+	ctx context.Context,
+	input rules.Input,
+	query ast.Body,
+	onResult func(string, report.Report),
+) (report.Report, error) {
 	l.startTimer(regalmetrics.RegalLintRego)
 	defer l.stopTimer(regalmetrics.RegalLintRego)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	var query ast.Body
-	if len(input.FileNames) > 1 {
-		query = lintAndCollectQuery
-	} else {
-		query = lintQuery
-	}
-
-	regoArgs, err := l.prepareRegoArgs(query)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed preparing query for linting: %w", err)
-	}
-
-	pq, err := rego.New(regoArgs...).PrepareForEval(ctx)
+	pq, err := l.prepareQuery(ctx, query)
 	if err != nil {
 		return report.Report{}, fmt.Errorf("failed preparing query for linting: %w", err)
 	}
@@ -683,6 +1258,7 @@ func (l Linter) lintWithRegoRules(ctx context.Context, input rules.Input) (repor
 	aggregate := report.Report{}
 	aggregate.Aggregates = make(map[string][]report.Aggregate)
 	aggregate.IgnoreDirectives = make(map[string]map[string][]string)
+	aggregate.Directives = make(map[string][]report.IgnoreDirective)
 
 	var wg sync.WaitGroup
 
@@ -745,8 +1321,13 @@ func (l Linter) lintWithRegoRules(ctx context.Context, input rules.Input) (repor
 			}
 
 			mu.Lock()
+			if onResult != nil {
+				onResult(name, result)
+			}
+
 			aggregate.Violations = append(aggregate.Violations, result.Violations...)
 			aggregate.Notices = append(aggregate.Notices, result.Notices...)
+			aggregate.Suppressed = append(aggregate.Suppressed, result.Suppressed...)
 
 			for k := range result.Aggregates {
 				aggregate.Aggregates[k] = append(aggregate.Aggregates[k], result.Aggregates[k]...)
@@ -756,6 +1337,10 @@ func (l Linter) lintWithRegoRules(ctx context.Context, input rules.Input) (repor
 				aggregate.IgnoreDirectives[k] = result.IgnoreDirectives[k]
 			}
 
+			for k := range result.Directives {
+				aggregate.Directives[k] = result.Directives[k]
+			}
+
 			if l.profiling {
 				aggregate.AddProfileEntries(result.AggregateProfile)
 			}
@@ -778,6 +1363,86 @@ func (l Linter) lintWithRegoRules(ctx context.Context, input rules.Input) (repor
 	}
 }
 
+// aggregateContextFiles returns the files found in the aggregate context paths, except for those ignored and
+// those linted.
+func (l Linter) aggregateContextFiles(ignore []string, linted []string) ([]string, error) {
+	paths, err := config.FilterIgnoredPaths(l.contextPaths, ignore, true, l.rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter aggregate context paths: %w", err)
+	}
+
+	return slices.DeleteFunc(paths, func(name string) bool {
+		return slices.Contains(linted, name) ||
+			(l.inputModules != nil && slices.Contains(l.inputModules.FileNames, name))
+	}), nil
+}
+
+// collectAggregateContext adds the aggregates and ignore directives of the context files provided to rep, for the
+// aggregate rules to consider. These are taken from the cache if a fingerprint function is provided and an entry is
+// found, and are otherwise collected by evaluating only the parts of the Rego rules collecting them.
+func (l Linter) collectAggregateContext(
+	ctx context.Context,
+	names []string,
+	fingerprint func(string) (string, error),
+	rep *report.Report,
+) error {
+	if rep.Aggregates == nil {
+		rep.Aggregates = make(map[string][]report.Aggregate)
+	}
+
+	if rep.IgnoreDirectives == nil {
+		rep.IgnoreDirectives = make(map[string]map[string][]string)
+	}
+
+	if fingerprint != nil {
+		hits := make(map[string]*cacheEntry)
+
+		var err error
+		if names, err = l.lookupCache(fingerprint, names, readFileContent, make(map[string]string), hits); err != nil {
+			return err
+		}
+
+		hitNames := util.Keys(hits)
+		slices.Sort(hitNames)
+
+		for _, name := range hitNames {
+			entry := hits[name]
+
+			for k := range entry.Aggregates {
+				rep.Aggregates[k] = append(rep.Aggregates[k], entry.Aggregates[k]...)
+			}
+
+			if entry.IgnoreDirectives != nil {
+				rep.IgnoreDirectives[name] = entry.IgnoreDirectives
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	input, err := rules.InputFromPaths(names)
+	if err != nil {
+		return fmt.Errorf("errors encountered when reading files: %w", err)
+	}
+
+	collected, err := l.lintWithRegoRules(ctx, input, collectQuery, nil)
+	if err != nil {
+		return err
+	}
+
+	for k := range collected.Aggregates {
+		rep.Aggregates[k] = append(rep.Aggregates[k], collected.Aggregates[k]...)
+	}
+
+	for name := range collected.IgnoreDirectives {
+		rep.IgnoreDirectives[name] = collected.IgnoreDirectives[name]
+	}
+
+	return nil
+}
+
 func (l Linter) lintWithRegoAggregateRules(
 	ctx context.Context,
 	aggregates map[string][]report.Aggregate,
@@ -789,12 +1454,7 @@ func (l Linter) lintWithRegoAggregateRules(
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	regoArgs, err := l.prepareRegoArgs(lintWithAggregatesQuery)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed preparing query for linting: %w", err)
-	}
-
-	pq, err := rego.New(regoArgs...).PrepareForEval(ctx)
+	pq, err := l.prepareQuery(ctx, lintWithAggregatesQuery)
 	if err != nil {
 		return report.Report{}, fmt.Errorf("failed preparing query for linting: %w", err)
 	}
@@ -892,7 +1552,9 @@ func (l Linter) combinedConfig() (*config.Config, error) {
 	return l.combinedCfg, nil
 }
 
-func (l Linter) enabledGoRules() ([]rules.Rule, error) {
+// enabledGoRules returns the Go rules enabled by conf, or the combined config of the linter if conf is nil,
+// and the enable/disable options of the linter.
+func (l Linter) enabledGoRules(conf *config.Config) ([]rules.Rule, error) {
 	var enabledGoRules []rules.Rule
 
 	// enabling/disabling all rules takes precedence and entirely disregards configuration
@@ -918,9 +1580,11 @@ func (l Linter) enabledGoRules() ([]rules.Rule, error) {
 		return enabledGoRules, nil
 	}
 
-	conf, err := l.combinedConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create merged config: %w", err)
+	if conf == nil {
+		var err error
+		if conf, err = l.combinedConfig(); err != nil {
+			return nil, fmt.Errorf("failed to create merged config: %w", err)
+		}
 	}
 
 	for _, rule := range rules.AllGoRules(*conf) {
@@ -956,6 +1620,149 @@ func (l Linter) enabledGoRules() ([]rules.Rule, error) {
 	return enabledGoRules, nil
 }
 
+// ExplainConfig returns the effective configuration resulting from merging the provided configuration with the
+// layers of user configuration, as returned by config.Resolver.Layers, and the enable/disable and ignore options of
+// the linter, which take precedence over any configuration.
+func (l Linter) ExplainConfig(layers []config.Layer) (*config.Explanation, error) {
+	regalBundle, err := l.getBundleByName("regal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regal bundle: %w", err)
+	}
+
+	explanation, err := config.Explain(regalBundle, layers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain config: %w", err)
+	}
+
+	for category, categoryRules := range explanation.Rules {
+		for title, settings := range categoryRules {
+			if flag := l.levelFlag(category, title); flag != "" {
+				level := config.LevelError
+				if strings.HasPrefix(flag, "--disable") {
+					level = config.LevelIgnore
+				}
+
+				settings["level"] = config.Setting{Value: level, Source: flag}
+			}
+		}
+	}
+
+	if len(l.ignoreFiles) > 0 {
+		explanation.Settings["ignore"] = config.Setting{
+			Value:  map[string]any{"files": l.ignoreFiles},
+			Source: "--ignore-files",
+		}
+	}
+
+	return explanation, nil
+}
+
+// KnownRules returns the rules known to the linter, i.e. the built-in rules, with the kind of each of their
+// attributes, and any custom rules loaded, for validating user configuration against.
+func (l Linter) KnownRules(ctx context.Context) (config.KnownRules, error) {
+	list, err := l.queryRules(ctx, knownRulesQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	known := config.BuiltinRules()
+
+	for _, rule := range list {
+		known.Add(rule[0], rule[1])
+	}
+
+	return known, nil
+}
+
+// aggregateRules returns the rules, both bundled and custom, reporting violations using aggregated data,
+// as category/title.
+func (l Linter) aggregateRules(ctx context.Context) (map[string]bool, error) {
+	list, err := l.queryRules(ctx, aggregateRulesQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregateRules := make(map[string]bool, len(list))
+	for _, rule := range list {
+		aggregateRules[rule[0]+"/"+rule[1]] = true
+	}
+
+	return aggregateRules, nil
+}
+
+// queryRules evaluates query, which binds rules to a set of category and title pairs.
+func (l Linter) queryRules(ctx context.Context, query ast.Body) ([][2]string, error) {
+	pq, err := l.prepareQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed preparing query: %w", err)
+	}
+
+	rs, err := pq.Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed evaluating query: %w", err)
+	}
+
+	if len(rs) != 1 {
+		return nil, fmt.Errorf("expected exactly one result, got %d", len(rs))
+	}
+
+	list, ok := rs[0].Bindings["rules"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected list, got %T", rs[0].Bindings["rules"])
+	}
+
+	result := make([][2]string, 0, len(list))
+
+	for _, item := range list {
+		rule, ok := item.([]interface{})
+		if !ok || len(rule) != 2 {
+			return nil, fmt.Errorf("expected category and title, got %v", item)
+		}
+
+		category, _ := rule[0].(string)
+		title, _ := rule[1].(string)
+
+		result = append(result, [2]string{category, title})
+	}
+
+	return result, nil
+}
+
+// ruleEnabled returns true if the rule is enabled in conf, taking into account the enable/disable options
+// of the linter, and where rules not found in conf, like custom rules, are enabled by default.
+func (l Linter) ruleEnabled(conf *config.Config, category, title string) bool {
+	if flag := l.levelFlag(category, title); flag != "" {
+		return !strings.HasPrefix(flag, "--disable")
+	}
+
+	if rule, ok := conf.Rules[category][title]; ok {
+		return rule.Level != "ignore"
+	}
+
+	return true
+}
+
+// levelFlag returns the enable/disable option of the linter determining the level of a rule, if any, following
+// the same precedence as for_rule in data.regal.config.
+func (l Linter) levelFlag(category, title string) string {
+	switch {
+	case util.Contains(l.disable, title):
+		return "--disable"
+	case l.disableAll && !util.Contains(l.enableCategory, category) && !util.Contains(l.enable, title):
+		return "--disable-all"
+	case util.Contains(l.disableCategory, category) && !util.Contains(l.enable, title):
+		return "--disable-category"
+	case util.Contains(l.enable, title):
+		return "--enable"
+	case l.enableAll && !util.Contains(l.disableCategory, category):
+		return "--enable-all"
+	case util.Contains(l.enableCategory, category):
+		return "--enable-category"
+	}
+
+	return ""
+}
+
 func (l Linter) getBundleByName(name string) (*bundle.Bundle, error) {
 	if l.ruleBundles == nil {
 		return nil, errors.New("no bundles loaded")
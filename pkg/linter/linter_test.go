@@ -4,16 +4,31 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
 
 	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/keys"
+	"github.com/open-policy-agent/opa/metrics"
 	"github.com/open-policy-agent/opa/topdown"
 
 	"github.com/styrainc/regal/internal/parse"
 	"github.com/styrainc/regal/internal/test"
 	"github.com/styrainc/regal/internal/testutil"
 	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
 	"github.com/styrainc/regal/pkg/rules"
 )
 
@@ -357,6 +372,179 @@ func TestLintWithUserConfigGoRuleIgnore(t *testing.T) {
 	}
 }
 
+func TestLintWithGoRuleIgnoreDirective(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+import rego.v1
+
+allow if input.method == "GET"
+
+# regal ignore:non-indexable-rule
+allow if input.size > 5
+
+allow if input.size < 1
+`
+
+	input := rules.NewInput(
+		map[string]string{"p.rego": policy},
+		map[string]*ast.Module{"p.rego": testutil.Must(parse.Module("p.rego", policy))(t)},
+	)
+
+	linter := NewLinter().
+		WithEnableAll(true).
+		WithInputModules(&input)
+
+	result := testutil.Must(linter.Lint(context.Background()))(t)
+
+	if len(result.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", result.Violations)
+	}
+
+	if result.Violations[0].Title != "non-indexable-rule" || result.Violations[0].Location.Row != 10 {
+		t.Errorf("expected non-indexable-rule violation at row 10, got %v", result.Violations[0])
+	}
+}
+
+func TestLintWithGoRuleIgnoreFileDirective(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+# regal ignore-file:performance/*
+
+import rego.v1
+
+allow if input.method == "GET"
+
+allow if input.size > 5
+`
+
+	input := rules.NewInput(
+		map[string]string{"p.rego": policy},
+		map[string]*ast.Module{"p.rego": testutil.Must(parse.Module("p.rego", policy))(t)},
+	)
+
+	linter := NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("non-indexable-rule").
+		WithInputModules(&input)
+
+	result := testutil.Must(linter.Lint(context.Background()))(t)
+
+	if len(result.Violations) != 0 {
+		t.Fatalf("expected no violations, got %v", result.Violations)
+	}
+}
+
+func TestLintUnusedIgnoreDirectives(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+import rego.v1
+
+# regal ignore:unresolved-import
+import data.unresolved
+
+# regal ignore:prefer-snake-case
+camelCase := 1
+
+# regal ignore:line-length
+short := 1
+
+# regal ignore:no-such-rule
+x := 2
+`
+
+	input := rules.NewInput(
+		map[string]string{"p.rego": policy},
+		map[string]*ast.Module{"p.rego": testutil.Must(parse.Module("p.rego", policy))(t)},
+	)
+
+	linter := NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case", "line-length", "unresolved-import", "unused-ignore-directive").
+		WithCache(NewInMemoryCache()).
+		WithInputModules(&input)
+
+	// as aggregate rules aren't evaluated when linting a single file, the directive
+	// for unresolved-import is not reported, and the results are the same when cached
+	expected := []string{
+		"p.rego:11:1: Ignore directive for line-length suppresses no violations",
+		"p.rego:14:1: Ignore directive references unknown rule no-such-rule",
+	}
+
+	for range 2 {
+		result := testutil.Must(linter.Lint(context.Background()))(t)
+
+		violations := make([]string, 0, len(result.Violations))
+		for _, violation := range result.Violations {
+			violations = append(violations, violation.Location.String()+": "+violation.Description)
+		}
+
+		slices.Sort(violations)
+
+		if !slices.Equal(violations, expected) {
+			t.Errorf("expected %v, got %v", expected, violations)
+		}
+	}
+}
+
+func TestLintWithConfigResolver(t *testing.T) {
+	t.Parallel()
+
+	policy := "package p\n\nimport rego.v1\n\ncamelCase   := 1\n"
+
+	input := rules.NewInput(
+		map[string]string{"p.rego": policy, "legacy/p.rego": policy},
+		map[string]*ast.Module{
+			"p.rego":        testutil.Must(parse.Module("p.rego", policy))(t),
+			"legacy/p.rego": testutil.Must(parse.Module("legacy/p.rego", policy))(t),
+		},
+	)
+
+	legacyConfig := &config.Config{
+		Rules: map[string]config.Category{
+			"style": {
+				"opa-fmt":           config.Rule{Level: "ignore"},
+				"prefer-snake-case": config.Rule{Level: "warning"},
+			},
+		},
+	}
+
+	linter := NewLinter().
+		WithInputModules(&input).
+		WithConfigResolver(func(filename string) (*config.Config, error) {
+			if strings.HasPrefix(filename, "legacy/") {
+				return legacyConfig, nil
+			}
+
+			return nil, nil //nolint:nilnil
+		})
+
+	result := testutil.Must(linter.Lint(context.Background()))(t)
+
+	levels := make(map[string]string)
+
+	for _, violation := range result.Violations {
+		if violation.Title == "opa-fmt" || violation.Title == "prefer-snake-case" {
+			levels[violation.Location.File+":"+violation.Title] = violation.Level
+		}
+	}
+
+	expected := map[string]string{
+		"p.rego:opa-fmt":                  "error",
+		"p.rego:prefer-snake-case":        "error",
+		"legacy/p.rego:prefer-snake-case": "warning",
+	}
+
+	if !maps.Equal(levels, expected) {
+		t.Errorf("expected violations %v, got %v", expected, levels)
+	}
+}
+
 func TestLintWithCustomRule(t *testing.T) {
 	t.Parallel()
 
@@ -377,6 +565,176 @@ func TestLintWithCustomRule(t *testing.T) {
 	}
 }
 
+func TestLintWithCustomRulesBundle(t *testing.T) {
+	t.Parallel()
+
+	input := test.InputPolicy("p.rego", "package p\n\nimport rego.v1\n")
+
+	path := filepath.Join(t.TempDir(), "rules.tar.gz")
+	if err := os.WriteFile(path, customRulesBundle(t, []string{"custom/regal/rules/naming"}, ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := testutil.Must(NewLinter().WithCustomRules([]string{path}).WithInputModules(&input).Lint(context.Background()))(t)
+
+	if len(result.Violations) != 1 || result.Violations[0].Title != "acme-corp-package" {
+		t.Fatalf("expected acme-corp-package violation, got %v", result.Violations)
+	}
+
+	// roots outside of custom/regal/rules are not allowed
+	outside := filepath.Join(t.TempDir(), "outside.tar.gz")
+	if err := os.WriteFile(outside, customRulesBundle(t, []string{"custom"}, ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewLinter().WithCustomRules([]string{outside}).WithInputModules(&input).Lint(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `bundle root "custom" is not within custom/regal/rules`) {
+		t.Errorf("expected error for bundle root outside of custom/regal/rules, got %v", err)
+	}
+}
+
+func TestLintWithSignedCustomRulesBundle(t *testing.T) {
+	t.Parallel()
+
+	input := test.InputPolicy("p.rego", "package p\n\nimport rego.v1\n")
+
+	dir := t.TempDir()
+	signed := filepath.Join(dir, "signed.tar.gz")
+	unsigned := filepath.Join(dir, "unsigned.tar.gz")
+
+	if err := os.WriteFile(signed, customRulesBundle(t, []string{"custom/regal/rules"}, "secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(unsigned, customRulesBundle(t, []string{"custom/regal/rules"}, ""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verification := func(key string) *bundle.VerificationConfig {
+		keyConfig := testutil.Must(keys.NewKeyConfig(key, "HS256", ""))(t)
+
+		return bundle.NewVerificationConfig(map[string]*keys.Config{"default": keyConfig}, "default", "", nil)
+	}
+
+	cases := map[string]struct {
+		path         string
+		verification *bundle.VerificationConfig
+		expectError  bool
+	}{
+		"signed bundle verified":     {path: signed, verification: verification("secret")},
+		"signed bundle not verified": {path: signed},
+		"signed bundle wrong key":    {path: signed, verification: verification("wrong"), expectError: true},
+		"unsigned bundle verified":   {path: unsigned, verification: verification("secret"), expectError: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			linter := NewLinter().
+				WithCustomRules([]string{tc.path}).
+				WithCustomRulesVerification(tc.verification).
+				WithInputModules(&input)
+
+			result, err := linter.Lint(context.Background())
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected error verifying bundle")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Violations) != 1 || result.Violations[0].Title != "acme-corp-package" {
+				t.Errorf("expected acme-corp-package violation, got %v", result.Violations)
+			}
+		})
+	}
+}
+
+func TestLintWithCustomRulesBundleFromURL(t *testing.T) {
+	t.Parallel()
+
+	input := test.InputPolicy("p.rego", "package p\n\nimport rego.v1\n")
+
+	bs := customRulesBundle(t, []string{"custom/regal/rules"}, "")
+
+	var downloads, notModified atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		downloads.Add(1)
+
+		_, _ = w.Write(bs)
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+
+	// the bundle is downloaded on the first run only, while later runs use the copy cached
+	for range 2 {
+		linter := NewLinter().
+			WithCustomRules([]string{server.URL + "/rules.tar.gz"}).
+			WithCustomRulesCacheDir(cacheDir).
+			WithInputModules(&input)
+
+		result := testutil.Must(linter.Lint(context.Background()))(t)
+
+		if len(result.Violations) != 1 || result.Violations[0].Title != "acme-corp-package" {
+			t.Fatalf("expected acme-corp-package violation, got %v", result.Violations)
+		}
+	}
+
+	if downloads.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("expected 1 download and 1 not modified response, got %d and %d", downloads.Load(), notModified.Load())
+	}
+}
+
+// customRulesBundle returns a bundle archive of the custom rule in testdata/custom.rego, with the roots
+// provided, and signed using key, unless empty.
+func customRulesBundle(t *testing.T, roots []string, key string) []byte {
+	t.Helper()
+
+	raw := testutil.Must(os.ReadFile(filepath.Join("testdata", "custom.rego")))(t)
+
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Roots: &roots},
+		Modules: []bundle.ModuleFile{{
+			URL:    "/custom.rego",
+			Path:   "/custom.rego",
+			Raw:    raw,
+			Parsed: testutil.Must(parse.Module("custom.rego", string(raw)))(t),
+		}},
+		Data: map[string]any{},
+	}
+
+	if key != "" {
+		if err := b.GenerateSignature(bundle.NewSigningConfig(key, "HS256", ""), "default", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.NewWriter(&buf).Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
 //go:embed testdata/*
 var testLintWithCustomEmbeddedRulesFS embed.FS
 
@@ -597,3 +955,552 @@ func TestEnabledRules(t *testing.T) {
 		t.Errorf("expected first enabled rule to be 'opa-fmt', got %q", enabledRules[1])
 	}
 }
+
+func TestLintWithCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	policyDir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	files := map[string]string{
+		"foo.rego": "package foo\n\nimport data.bar\n\ncamelCase := true\n",
+		"bar.rego": "package bar\n\nimport data.foo.camelCase\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(policyDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lint := func() (report.Report, map[string]any) {
+		t.Helper()
+
+		m := metrics.New()
+
+		rep := testutil.Must(NewLinter().
+			WithDisableAll(true).
+			WithEnabledRules("prefer-snake-case", "prefer-package-imports", "opa-fmt").
+			WithInputPaths([]string{policyDir}).
+			WithCache(NewCache(cacheDir)).
+			WithMetrics(m).
+			Lint(ctx))(t)
+
+		return rep, m.All()
+	}
+
+	cold, coldMetrics := lint()
+	warm, warmMetrics := lint()
+
+	if coldMetrics["counter_regal_lint_cache_misses"] != uint64(2) {
+		t.Errorf("expected 2 cache misses on cold run, got %v", coldMetrics["counter_regal_lint_cache_misses"])
+	}
+
+	if warmMetrics["counter_regal_lint_cache_hits"] != uint64(2) {
+		t.Errorf("expected 2 cache hits on warm run, got %v", warmMetrics["counter_regal_lint_cache_hits"])
+	}
+
+	if len(cold.Violations) != 2 {
+		t.Fatalf("expected 2 violations, got %d", len(cold.Violations))
+	}
+
+	sortViolations := func(vs []report.Violation) {
+		slices.SortFunc(vs, func(a, b report.Violation) int {
+			return strings.Compare(a.Location.String()+a.Title, b.Location.String()+b.Title)
+		})
+	}
+
+	sortViolations(cold.Violations)
+	sortViolations(warm.Violations)
+
+	if diff := cmp.Diff(cold.Violations, warm.Violations); diff != "" {
+		t.Errorf("expected cached report to equal cold report (-cold +warm):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(cold.Summary, warm.Summary); diff != "" {
+		t.Errorf("expected cached summary to equal cold summary (-cold +warm):\n%s", diff)
+	}
+
+	// changing one file should only invalidate the cache for that file
+	err := os.WriteFile(filepath.Join(policyDir, "foo.rego"), []byte("package foo\n\nsnake_case := true\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, changedMetrics := lint()
+
+	if changedMetrics["counter_regal_lint_cache_hits"] != uint64(1) {
+		t.Errorf("expected 1 cache hit after change, got %v", changedMetrics["counter_regal_lint_cache_hits"])
+	}
+
+	// the aggregate violation in bar.rego is still reported, and the prefer-snake-case violation in foo.rego is gone
+	if len(changed.Violations) != 1 || changed.Violations[0].Title != "prefer-package-imports" {
+		t.Errorf("expected only prefer-package-imports violation, got %v", changed.Violations)
+	}
+}
+
+func TestLintWithAggregateContextPaths(t *testing.T) {
+	t.Parallel()
+
+	policyDir := t.TempDir()
+
+	files := map[string]string{
+		"foo.rego": "package foo\n\nimport data.bar\n\ncamelCase := true\n",
+		"bar.rego": "package bar\n\nimport data.foo.camelCase\n",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(policyDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lint := func(path string, contextPaths []string) []string {
+		t.Helper()
+
+		rep := testutil.Must(NewLinter().
+			WithDisableAll(true).
+			WithEnabledRules("prefer-snake-case", "prefer-package-imports").
+			WithInputPaths([]string{filepath.Join(policyDir, path)}).
+			WithAggregateContextPaths(contextPaths).
+			Lint(context.Background()))(t)
+
+		violations := make([]string, 0, len(rep.Violations))
+		for _, violation := range rep.Violations {
+			violations = append(violations, filepath.Base(violation.Location.File)+":"+violation.Title)
+		}
+
+		slices.Sort(violations)
+
+		return violations
+	}
+
+	// without context, a single file is not enough for aggregate rules to be evaluated
+	if violations := lint("bar.rego", nil); len(violations) != 0 {
+		t.Errorf("expected no violations without context, got %v", violations)
+	}
+
+	if violations := lint("bar.rego", []string{policyDir}); !slices.Equal(violations, []string{
+		"bar.rego:prefer-package-imports",
+	}) {
+		t.Errorf("expected aggregate violation in linted file, got %v", violations)
+	}
+
+	// violations in files providing context are not reported
+	if violations := lint("foo.rego", []string{policyDir}); !slices.Equal(violations, []string{
+		"foo.rego:prefer-snake-case",
+	}) {
+		t.Errorf("expected only violations in linted file, got %v", violations)
+	}
+}
+
+func TestLintWithCacheProgramRules(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	policyDir := t.TempDir()
+
+	write := func(name, content string) {
+		t.Helper()
+
+		if err := os.WriteFile(filepath.Join(policyDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("a.rego", "package a\n\nimport rego.v1\n\nf(x) := x\n")
+	write("b.rego", "package b\n\nimport rego.v1\n\nallow if data.a.f(1, 2, 3)\n")
+
+	linter := NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("compile-error").
+		WithInputPaths([]string{policyDir}).
+		WithCache(NewCache(t.TempDir()))
+
+	compileErrors := func() []string {
+		t.Helper()
+
+		files := make([]string, 0)
+
+		for _, violation := range testutil.Must(linter.Lint(ctx))(t).Violations {
+			files = append(files, filepath.Base(violation.Location.File))
+		}
+
+		return files
+	}
+
+	if files := compileErrors(); !slices.Equal(files, []string{"b.rego"}) {
+		t.Fatalf("expected compile error in b.rego, got errors in %v", files)
+	}
+
+	// b.rego is found in the cache, but the error in it is gone with the change to a.rego
+	write("a.rego", "package a\n\nimport rego.v1\n\nf(x, y) := x + y\n")
+
+	if files := compileErrors(); len(files) != 0 {
+		t.Errorf("expected no compile errors after change, got errors in %v", files)
+	}
+
+	// and comes back with a.rego changed back, while every file is found in the cache
+	write("a.rego", "package a\n\nimport rego.v1\n\nf(x) := x\n")
+
+	if files := compileErrors(); !slices.Equal(files, []string{"b.rego"}) {
+		t.Errorf("expected compile error in b.rego from cached files, got errors in %v", files)
+	}
+}
+
+func TestCacheFingerprintSchemas(t *testing.T) {
+	t.Parallel()
+
+	schemasDir := t.TempDir()
+	schemaFile := filepath.Join(schemasDir, "request.json")
+
+	if err := os.WriteFile(schemaFile, []byte(`{"type": "object"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := config.Config{Schemas: &config.Schemas{
+		Directory: schemasDir,
+		Input:     []config.SchemaMapping{{Package: "authz", Schema: "request"}},
+	}}
+
+	linter := NewLinter()
+	before := testutil.Must(linter.cacheFingerprint(&conf))(t)
+
+	if err := os.WriteFile(schemaFile, []byte(`{"type": "string"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := testutil.Must(linter.cacheFingerprint(&conf))(t)
+	if changed == before {
+		t.Error("expected fingerprint to change with the contents of a schema")
+	}
+
+	conf.Schemas.Input[0].Package = "other"
+
+	if remapped := testutil.Must(linter.cacheFingerprint(&conf))(t); remapped == changed {
+		t.Error("expected fingerprint to change with the schema mappings")
+	}
+}
+
+func TestCacheEntryExpiresWithIgnoreDirectives(t *testing.T) {
+	t.Parallel()
+
+	entry := &cacheEntry{Expires: directivesExpiry([]report.IgnoreDirective{
+		{Kind: "ignore", Until: "2030-06-01"},
+		{Kind: "ignore", Until: "2030-01-01"},
+		{Kind: "ignore", Until: "2000-01-01", Expired: true},
+		{Kind: "ignore", Until: "tomorrow"},
+		{Kind: "ignore"},
+	})}
+
+	if entry.Expires != "2030-01-01" {
+		t.Fatalf("expected entry to expire 2030-01-01, got %q", entry.Expires)
+	}
+
+	if entry.stale(time.Date(2029, 12, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected entry not to be stale before any directive expires")
+	}
+
+	if entry.stale(time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expected entry not to be stale on the day a directive expires")
+	}
+
+	if !entry.stale(time.Date(2030, 1, 2, 0, 0, 1, 0, time.UTC)) {
+		t.Error("expected entry to be stale once a directive has expired")
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	t.Parallel()
+
+	cache := NewCache(t.TempDir())
+
+	for _, key := range []string{"old", "new"} {
+		if err := cache.put(key+".rego", key, &cacheEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unused := time.Now().Add(-cacheMaxAge - time.Hour)
+	if err := os.Chtimes(cache.entryPath("old"), unused, unused); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.prune(cacheMaxAge); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.get("old.rego", "old"); ok {
+		t.Error("expected entry not used for longer than max age to be removed")
+	}
+
+	if _, ok := cache.get("new.rego", "new"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+}
+
+func TestInMemoryCacheReplacesEntries(t *testing.T) {
+	t.Parallel()
+
+	cache := NewInMemoryCache()
+
+	for _, key := range []string{"v1", "v2"} {
+		if err := cache.put("p.rego", key, &cacheEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cache.put("q.rego", "v1", &cacheEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.get("p.rego", "v1"); ok {
+		t.Error("expected entry of changed file to be replaced")
+	}
+
+	if _, ok := cache.get("p.rego", "v2"); !ok {
+		t.Error("expected entry of latest version of file")
+	}
+
+	cache.retain(map[string]string{"p.rego": "v2"})
+
+	if len(cache.entries) != 1 {
+		t.Errorf("expected only entry of file retained to be kept, got %d entries", len(cache.entries))
+	}
+}
+
+func TestLintWithQueryReuse(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	policyDir := t.TempDir()
+	policyFile := filepath.Join(policyDir, "p.rego")
+
+	if err := os.WriteFile(policyFile, []byte("package p\n\ncamelCase := true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case").
+		WithInputPaths([]string{policyDir}).
+		WithCache(NewInMemoryCache()).
+		WithQueryReuse(true)
+
+	rep := testutil.Must(linter.Lint(ctx))(t)
+	if len(rep.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d", len(rep.Violations))
+	}
+
+	numPrepared := len(linter.prepared.queries)
+	if numPrepared == 0 {
+		t.Fatal("expected queries to have been prepared")
+	}
+
+	if err := os.WriteFile(policyFile, []byte("package p\n\nsnake_case   :=   true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rep = testutil.Must(linter.Lint(ctx))(t)
+	if len(rep.Violations) != 0 {
+		t.Fatalf("expected no violations after change, got %d", len(rep.Violations))
+	}
+
+	if len(linter.prepared.queries) != numPrepared {
+		t.Errorf("expected prepared queries to be reused, got %d prepared", len(linter.prepared.queries))
+	}
+
+	// a copy of the linter with different rules enabled must not reuse the same query
+	rep = testutil.Must(linter.WithEnabledRules("opa-fmt").Lint(ctx))(t)
+	if len(rep.Violations) != 1 || rep.Violations[0].Title != "opa-fmt" {
+		t.Errorf("expected opa-fmt violation, got %v", rep.Violations)
+	}
+
+	if len(linter.prepared.queries) == numPrepared {
+		t.Error("expected new query to be prepared when enabled rules changed")
+	}
+}
+
+func TestExplainConfig(t *testing.T) {
+	t.Parallel()
+
+	layers := []config.Layer{
+		{Source: "base.yaml", Settings: map[string]any{
+			"rules": map[string]any{
+				"default": map[string]any{"level": "warning"},
+				"style": map[string]any{
+					"default":     map[string]any{"max-warnings": 2},
+					"line-length": map[string]any{"max-line-length": 100},
+				},
+			},
+		}},
+		{Source: ".regal/config.yaml", Settings: map[string]any{
+			"rules": map[string]any{
+				"idiomatic": map[string]any{"default": map[string]any{"level": "info"}},
+				"style":     map[string]any{"line-length": map[string]any{"level": "hint"}},
+			},
+			"ignore": map[string]any{"files": []any{"gen/*"}},
+		}},
+	}
+
+	linter := NewLinter().WithDisabledCategories("imports").WithEnabledRules("no-defined-entrypoint")
+
+	explanation := testutil.Must(linter.ExplainConfig(layers))(t)
+
+	expected := map[string]config.Setting{
+		"style/line-length/level":           {Value: "hint", Source: ".regal/config.yaml"},
+		"style/line-length/max-line-length": {Value: float64(100), Source: "base.yaml"},
+		// a category default without a level leaves the provided level in place
+		"style/opa-fmt/level":                   {Value: "error", Source: "provided"},
+		"idiomatic/use-in-operator/level":       {Value: "info", Source: "category default from .regal/config.yaml"},
+		"bugs/constant-condition/level":         {Value: "warning", Source: "global default from base.yaml"},
+		"imports/avoid-importing-input/level":   {Value: "ignore", Source: "--disable-category"},
+		"idiomatic/no-defined-entrypoint/level": {Value: "error", Source: "--enable"},
+	}
+
+	for path, exp := range expected {
+		parts := strings.Split(path, "/")
+
+		if got := explanation.Rules[parts[0]][parts[1]][parts[2]]; !cmp.Equal(got, exp) {
+			t.Errorf("%s: expected %v, got %v", path, exp, got)
+		}
+	}
+
+	if got := explanation.Settings["ignore"].Source; got != ".regal/config.yaml" {
+		t.Errorf("expected ignore from .regal/config.yaml, got %q", got)
+	}
+
+	if got := explanation.CategoryDefaults["style"]["max-warnings"]; got.Source != "base.yaml" {
+		t.Errorf("expected style max-warnings default from base.yaml, got %v", got)
+	}
+
+	if exp := []string{"provided", "base.yaml", ".regal/config.yaml"}; !slices.Equal(explanation.Sources, exp) {
+		t.Errorf("expected sources %v, got %v", exp, explanation.Sources)
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	t.Parallel()
+
+	var conf config.Config
+
+	if err := yaml.Unmarshal([]byte(`rules:
+  default:
+    max-warnings: 5
+  style:
+    default:
+      max-warnings: 1
+    prefer-snake-case:
+      max-violations: 1
+  bugs:
+    constant-condition:
+      max-violations: 0
+`), &conf); err != nil {
+		t.Fatal(err)
+	}
+
+	violations := []report.Violation{
+		{Category: "style", Title: "prefer-snake-case", Level: "error"},
+		{Category: "style", Title: "prefer-snake-case", Level: "error"},
+		{Category: "style", Title: "line-length", Level: "warning"},
+		{Category: "testing", Title: "print-or-trace-call", Level: "warning"},
+	}
+
+	expected := []report.Budget{
+		{Scope: "global", Level: "warning", Limit: 5, Count: 2},
+		{Scope: "category", Name: "style", Level: "warning", Limit: 1, Count: 1},
+		{Scope: "rule", Name: "bugs/constant-condition", Limit: 0, Count: 0},
+		{Scope: "rule", Name: "style/prefer-snake-case", Limit: 1, Count: 2},
+	}
+
+	budgets := Budgets(&conf, violations)
+	if diff := cmp.Diff(expected, budgets); diff != "" {
+		t.Errorf("unexpected budgets (-want, +got):\n%s", diff)
+	}
+
+	exceeded := make([]string, 0)
+
+	for _, budget := range budgets {
+		if budget.Exceeded() {
+			exceeded = append(exceeded, budget.String())
+		}
+	}
+
+	if exp := []string{"style/prefer-snake-case: 2 of 1 allowed, exceeded by 1"}; !slices.Equal(exp, exceeded) {
+		t.Errorf("expected exceeded budgets %v, got %v", exp, exceeded)
+	}
+
+	if budgets := Budgets(&config.Config{}, violations); budgets != nil {
+		t.Errorf("expected no budgets without config, got %v", budgets)
+	}
+}
+
+func TestResolvedBudgets(t *testing.T) {
+	t.Parallel()
+
+	parse := func(s string) *config.Config {
+		t.Helper()
+
+		var conf config.Config
+		if err := yaml.Unmarshal([]byte(s), &conf); err != nil {
+			t.Fatal(err)
+		}
+
+		return &conf
+	}
+
+	root := parse("rules:\n  default:\n    max-warnings: 5\n  style:\n    line-length:\n      max-violations: 1\n")
+	// a nested config, or overrides, lowering the global budget and adding a budget of its own
+	nested := parse(`rules:
+  default:
+    max-warnings: 1
+  style:
+    line-length:
+      max-violations: 1
+    prefer-snake-case:
+      max-violations: 0
+`)
+
+	resolve := func(file string) (*config.Config, error) {
+		if strings.HasPrefix(file, "legacy/") {
+			return nested, nil
+		}
+
+		return nil, nil //nolint:nilnil
+	}
+
+	violations := []report.Violation{
+		{Category: "style", Title: "line-length", Level: "warning", Location: report.Location{File: "p.rego"}},
+		{Category: "style", Title: "line-length", Level: "warning", Location: report.Location{File: "legacy/p.rego"}},
+		{Category: "style", Title: "prefer-snake-case", Level: "warning", Location: report.Location{File: "legacy/p.rego"}},
+	}
+
+	expected := []report.Budget{
+		{Scope: "global", Level: "warning", Limit: 1, Count: 2},
+		{Scope: "global", Level: "warning", Limit: 5, Count: 1},
+		{Scope: "rule", Name: "style/line-length", Limit: 1, Count: 2},
+		{Scope: "rule", Name: "style/prefer-snake-case", Limit: 0, Count: 1},
+	}
+
+	budgets := testutil.Must(ResolvedBudgets(root, resolve, violations))(t)
+	if diff := cmp.Diff(expected, budgets); diff != "" {
+		t.Errorf("unexpected budgets (-want, +got):\n%s", diff)
+	}
+}
+
+func TestKnownRules(t *testing.T) {
+	t.Parallel()
+
+	known := testutil.Must(NewLinter().
+		WithCustomRulesFromFS(testLintWithCustomEmbeddedRulesFS, "testdata").
+		KnownRules(context.Background()))(t)
+
+	if _, ok := known["naming"]["acme-corp-package"]; !ok {
+		t.Error("expected custom rule naming/acme-corp-package to be known")
+	}
+
+	if kind := known["style"]["line-length"]["max-line-length"]; kind != config.KindInteger {
+		t.Errorf("expected max-line-length of line-length to be %s, got %q", config.KindInteger, kind)
+	}
+}
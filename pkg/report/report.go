@@ -46,6 +46,19 @@ type Violation struct {
 	IsAggregate      bool              `json:"-"`
 }
 
+// IgnoreDirective describes an ignore directive comment found in a file, like `# regal ignore:line-length`.
+type IgnoreDirective struct {
+	// Kind is the kind of directive: ignore, ignore-file, disable or enable.
+	Kind string `json:"kind"`
+	// Rules are the rules referenced, by title, by category and title, or by category, like style/*.
+	Rules    []string `json:"rules"`
+	Location Location `json:"location"`
+	Reason   string   `json:"reason,omitempty"`
+	// Until is the date, as YYYY-MM-DD, after which the directive expires and is no longer applied.
+	Until   string `json:"until,omitempty"`
+	Expired bool   `json:"expired,omitempty"`
+}
+
 // Notice describes any notice found by Regal.
 type Notice struct {
 	Title       string `json:"title"`
@@ -62,10 +75,78 @@ type Notice struct {
 type Aggregate map[string]any
 
 type Summary struct {
-	FilesScanned  int `json:"files_scanned"`
-	FilesFailed   int `json:"files_failed"`
-	RulesSkipped  int `json:"rules_skipped"`
-	NumViolations int `json:"num_violations"`
+	FilesScanned  int      `json:"files_scanned"`
+	FilesFailed   int      `json:"files_failed"`
+	RulesSkipped  int      `json:"rules_skipped"`
+	NumViolations int      `json:"num_violations"`
+	Budgets       []Budget `json:"budgets,omitempty"`
+}
+
+// Budget scopes, i.e. what the violations counted against a budget have in common.
+const (
+	BudgetScopeGlobal   = "global"
+	BudgetScopeCategory = "category"
+	BudgetScopeRule     = "rule"
+)
+
+// Budget is the number of violations allowed for a rule, a category or globally, as configured by the
+// max-violations and max-warnings settings. Violations counted against a budget don't fail the lint run
+// unless the budget is exceeded.
+type Budget struct {
+	Scope string `json:"scope"`
+	// Name is the name of the budgeted rule, on the form category/title, or of the category.
+	// Empty for global budgets.
+	Name string `json:"name,omitempty"`
+	// Level is the level of the violations counted, or empty if violations of any level are counted.
+	Level string `json:"level,omitempty"`
+	Limit int    `json:"limit"`
+	Count int    `json:"count"`
+}
+
+// Covers returns true if violation is counted against the budget.
+func (b Budget) Covers(violation Violation) bool {
+	if b.Level != "" && violation.Level != b.Level {
+		return false
+	}
+
+	switch b.Scope {
+	case BudgetScopeRule:
+		return violation.Category+"/"+violation.Title == b.Name
+	case BudgetScopeCategory:
+		return violation.Category == b.Name
+	default:
+		return true
+	}
+}
+
+// Exceeded returns true if more violations than allowed were counted against the budget.
+func (b Budget) Exceeded() bool {
+	return b.Count > b.Limit
+}
+
+// String returns a description of the budget, and how far it is from its limit.
+func (b Budget) String() string {
+	counted := "violations"
+	if b.Level != "" {
+		counted = b.Level + "s"
+	}
+
+	var subject string
+
+	switch b.Scope {
+	case BudgetScopeRule:
+		subject = b.Name
+	case BudgetScopeCategory:
+		subject = b.Name + " " + counted
+	default:
+		subject = "all " + counted
+	}
+
+	if b.Exceeded() {
+		return fmt.Sprintf("%s: %d of %d allowed, exceeded by %d", subject, b.Count, b.Limit, b.Count-b.Limit)
+	}
+
+	return fmt.Sprintf("%s: %d of %d allowed, %d remaining", subject, b.Count, b.Limit, b.Limit-b.Count)
 }
 
 // Report aggregate of Violation as returned by a linter run.
@@ -80,6 +161,10 @@ type Report struct {
 	AggregateProfile map[string]ProfileEntry        `json:"-"`
 	Profile          []ProfileEntry                 `json:"profile,omitempty"`
 	IgnoreDirectives map[string]map[string][]string `json:"ignore_directives,omitempty"`
+	// Directives are the ignore directive comments of each file, and Suppressed the violations they suppressed.
+	// Like IgnoreDirectives, these are used only while linting, and not included in the final report.
+	Directives map[string][]IgnoreDirective `json:"directives,omitempty"`
+	Suppressed []Violation                  `json:"suppressed,omitempty"`
 }
 
 // ProfileEntry is a single entry of profiling information, keyed by location.
@@ -3,19 +3,29 @@ package reporter
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"html/template"
 	"io"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/jstemmer/go-junit-report/v2/junit"
 	"github.com/olekukonko/tablewriter"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 
+	"github.com/styrainc/regal/internal/embeds"
 	"github.com/styrainc/regal/internal/novelty"
+	"github.com/styrainc/regal/internal/util"
+	"github.com/styrainc/regal/pkg/config"
 	"github.com/styrainc/regal/pkg/report"
 )
 
@@ -27,9 +37,21 @@ type Reporter interface {
 
 // PrettyReporter is a Reporter for representing reports as tables.
 type PrettyReporter struct {
-	out io.Writer
+	out        io.Writer
+	codeFrames bool
+	groupBy    string
 }
 
+const (
+	// GroupByFile groups violations by the file they were found in.
+	GroupByFile = "file"
+	// GroupByRule groups violations by the rule that reported them.
+	GroupByRule = "rule"
+)
+
+// codeFrameContextLines is the number of lines shown before and after the lines of a violation in code frames.
+const codeFrameContextLines = 2
+
 // CompactReporter reports violations in a compact table.
 type CompactReporter struct {
 	out io.Writer
@@ -61,11 +83,44 @@ type JUnitReporter struct {
 	out io.Writer
 }
 
+// CheckstyleReporter reports violations in the Checkstyle XML format
+// (https://checkstyle.sourceforge.io/), as consumed by e.g. Jenkins and SonarQube.
+type CheckstyleReporter struct {
+	out io.Writer
+}
+
+// CodeClimateReporter reports violations in the Code Climate JSON format
+// (https://github.com/codeclimate/platform/blob/master/spec/analyzers/SPEC.md), as used by e.g. GitLab Code Quality.
+type CodeClimateReporter struct {
+	out io.Writer
+}
+
+// HTMLReporter reports violations as a single, self-contained, HTML page.
+type HTMLReporter struct {
+	out io.Writer
+}
+
 // NewPrettyReporter creates a new PrettyReporter.
 func NewPrettyReporter(out io.Writer) PrettyReporter {
 	return PrettyReporter{out: out}
 }
 
+// WithCodeFrames enables printing a code frame for each violation, showing the lines surrounding the location
+// of the violation, with the violation itself underlined.
+func (tr PrettyReporter) WithCodeFrames(enabled bool) PrettyReporter {
+	tr.codeFrames = enabled
+
+	return tr
+}
+
+// WithGroupBy groups the violations printed by either file (GroupByFile) or rule (GroupByRule).
+// An empty string means no grouping, which prints violations in the order reported.
+func (tr PrettyReporter) WithGroupBy(groupBy string) PrettyReporter {
+	tr.groupBy = groupBy
+
+	return tr
+}
+
 // NewCompactReporter creates a new CompactReporter.
 func NewCompactReporter(out io.Writer) CompactReporter {
 	return CompactReporter{out: out}
@@ -96,9 +151,29 @@ func NewJUnitReporter(out io.Writer) JUnitReporter {
 	return JUnitReporter{out: out}
 }
 
+// NewCheckstyleReporter creates a new CheckstyleReporter.
+func NewCheckstyleReporter(out io.Writer) CheckstyleReporter {
+	return CheckstyleReporter{out: out}
+}
+
+// NewCodeClimateReporter creates a new CodeClimateReporter.
+func NewCodeClimateReporter(out io.Writer) CodeClimateReporter {
+	return CodeClimateReporter{out: out}
+}
+
+// NewHTMLReporter creates a new HTMLReporter.
+func NewHTMLReporter(out io.Writer) HTMLReporter {
+	return HTMLReporter{out: out}
+}
+
 // Publish prints a pretty report to the configured output.
 func (tr PrettyReporter) Publish(_ context.Context, r report.Report) error {
-	table := buildPrettyViolationsTable(r.Violations)
+	var table string
+	if tr.codeFrames || tr.groupBy != "" {
+		table = tr.buildViolationsWithFrames(r.Violations)
+	} else {
+		table = buildPrettyViolationsTable(r.Violations)
+	}
 
 	pluralScanned := ""
 	if r.Summary.FilesScanned == 0 || r.Summary.FilesScanned > 1 {
@@ -148,6 +223,24 @@ func (tr PrettyReporter) Publish(_ context.Context, r report.Report) error {
 		}
 	}
 
+	if len(r.Summary.Budgets) > 0 {
+		if !strings.HasSuffix(footer, "\n") {
+			footer += "\n"
+		}
+
+		footer += "Violation budgets:\n"
+
+		red := color.New(color.FgRed).SprintFunc()
+
+		for _, budget := range r.Summary.Budgets {
+			if budget.Exceeded() {
+				footer += fmt.Sprintf("- %s\n", red(budget.String()))
+			} else {
+				footer += fmt.Sprintf("- %s\n", budget.String())
+			}
+		}
+	}
+
 	_, err := fmt.Fprint(tr.out, table+footer+"\n")
 
 	return err
@@ -188,13 +281,9 @@ func buildPrettyViolationsTable(violations []report.Violation) string {
 
 	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
 
 	for i, violation := range violations {
-		description := red(violation.Description)
-		if violation.Level == "warning" {
-			description = yellow(violation.Description)
-		}
+		description := levelColor(violation.Level)(violation.Description)
 
 		table.Append([]string{yellow("Rule:"), violation.Title})
 		table.Append([]string{yellow("Description:"), description})
@@ -228,6 +317,212 @@ func buildPrettyViolationsTable(violations []report.Violation) string {
 	return sb.String() + end
 }
 
+// levelColor returns the function used to color the description and underline of violations of level.
+func levelColor(level string) func(a ...any) string {
+	switch level {
+	case config.LevelWarning:
+		return color.New(color.FgYellow).SprintFunc()
+	case config.LevelInfo:
+		return color.New(color.FgBlue).SprintFunc()
+	case config.LevelHint:
+		return color.New(color.Faint).SprintFunc()
+	default:
+		return color.New(color.FgRed).SprintFunc()
+	}
+}
+
+// buildViolationsWithFrames prints each violation like buildPrettyViolationsTable, optionally grouped and followed
+// by a code frame.
+func (tr PrettyReporter) buildViolationsWithFrames(violations []report.Violation) string {
+	sb := &strings.Builder{}
+	header := color.New(color.Bold, color.Underline).SprintFunc()
+	sources := make(map[string][]string)
+
+	for i, group := range tr.groupViolations(violations) {
+		if group.Key != "" {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+
+			fmt.Fprintf(sb, "%s\n\n", header(fmt.Sprintf("%s (%d)", group.Key, len(group.Violations))))
+		}
+
+		for j, violation := range group.Violations {
+			sb.WriteString(strings.TrimSuffix(buildPrettyViolationsTable([]report.Violation{violation}), "\n"))
+
+			if tr.codeFrames {
+				if frame := buildCodeFrame(violation, sourceLines(sources, violation.Location)); frame != "" {
+					sb.WriteString("\n" + frame)
+				}
+			}
+
+			if j+1 < len(group.Violations) {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (tr PrettyReporter) groupViolations(violations []report.Violation) []ViolationGroup {
+	byLocation := func(a, b report.Violation) int {
+		if a.Location.File != b.Location.File {
+			return strings.Compare(a.Location.File, b.Location.File)
+		}
+
+		if a.Location.Row != b.Location.Row {
+			return a.Location.Row - b.Location.Row
+		}
+
+		return a.Location.Column - b.Location.Column
+	}
+
+	var groups []ViolationGroup
+
+	switch tr.groupBy {
+	case GroupByFile:
+		groups = groupViolations(func(v report.Violation) string { return v.Location.File })(violations)
+	case GroupByRule:
+		groups = groupViolations(func(v report.Violation) string { return v.Category + "/" + v.Title })(violations)
+	default:
+		return []ViolationGroup{{Violations: violations}}
+	}
+
+	for _, group := range groups {
+		slices.SortStableFunc(group.Violations, byLocation)
+	}
+
+	return groups
+}
+
+// sourceLines returns the lines of the file where the violation was found, reading them from disk only
+// once per file. If the file can't be read, the text of the location is used, if available.
+func sourceLines(sources map[string][]string, location report.Location) []string {
+	lines, ok := sources[location.File]
+	if !ok {
+		if bs, err := os.ReadFile(location.File); err == nil {
+			lines = strings.Split(strings.ReplaceAll(string(bs), "\r\n", "\n"), "\n")
+		}
+
+		sources[location.File] = lines
+	}
+
+	if lines != nil {
+		return lines
+	}
+
+	if location.Text == nil || location.Row == 0 {
+		return nil
+	}
+
+	// pad with empty lines, so that the text is found at the row of the location
+	return append(make([]string, location.Row-1), strings.Split(*location.Text, "\n")...)
+}
+
+// buildCodeFrame returns a code frame for the violation, showing the lines of the violation and the lines
+// surrounding them, with line numbers in a gutter, and the violation underlined from its start column to its
+// end column, when known. An empty string is returned when no source is available for the violation.
+func buildCodeFrame(violation report.Violation, lines []string) string {
+	loc := violation.Location
+
+	first, endRow, last, ok := codeFrameRows(loc, lines)
+	if !ok {
+		return ""
+	}
+
+	endCol := 0
+	if loc.End != nil && loc.End.Row >= loc.Row {
+		endCol = loc.End.Column
+	}
+
+	highlight := levelColor(violation.Level)
+	faint := color.New(color.Faint).SprintFunc()
+	width := len(strconv.Itoa(last))
+	sb := &strings.Builder{}
+
+	for row := first; row <= last; row++ {
+		line := lines[row-1]
+		number := fmt.Sprintf("%*d", width, row)
+
+		if row < loc.Row || row > endRow {
+			fmt.Fprintf(sb, "  %s %s %s\n", faint(number), faint("|"), line)
+
+			continue
+		}
+
+		fmt.Fprintf(sb, "%s %s %s %s\n", highlight(">"), number, faint("|"), line)
+
+		// underline from the start column on the first row, or the first non-whitespace character on following
+		// rows, to the end column on the last row, or the end of the line on preceding rows
+		start := len(line) - len(strings.TrimLeft(line, " \t")) + 1
+		if row == loc.Row {
+			start = max(loc.Column, 1)
+		}
+
+		end := len(line) + 1
+		if row == endRow && endCol > start {
+			end = min(endCol, len(line)+1)
+		}
+
+		if end <= start {
+			end = start + 1
+		}
+
+		fmt.Fprintf(sb, "  %s %s %s%s\n",
+			strings.Repeat(" ", width),
+			faint("|"),
+			underlinePadding(line, start-1),
+			highlight(strings.Repeat("^", end-start)),
+		)
+	}
+
+	return sb.String()
+}
+
+// codeFrameRows returns the first and last row of a code frame for location, along with the last row of the location
+// itself, or false if the location is not found in lines.
+func codeFrameRows(loc report.Location, lines []string) (first, endRow, last int, ok bool) {
+	if loc.Row < 1 || loc.Row > len(lines) {
+		return 0, 0, 0, false
+	}
+
+	endRow = loc.Row
+	if loc.End != nil && loc.End.Row >= loc.Row {
+		endRow = min(loc.End.Row, len(lines))
+	}
+
+	first = max(1, loc.Row-codeFrameContextLines)
+	last = min(len(lines), endRow+codeFrameContextLines)
+
+	// trailing empty lines (like the one following the final newline) aren't interesting context
+	for last > endRow && strings.TrimSpace(lines[last-1]) == "" {
+		last--
+	}
+
+	return first, endRow, last, true
+}
+
+// underlinePadding returns whitespace matching the width of the first n characters of line,
+// keeping any tabs so that the underline is aligned with the text above it.
+func underlinePadding(line string, n int) string {
+	padding := make([]byte, 0, n)
+
+	for i := 0; i < n; i++ {
+		if i < len(line) && line[i] == '\t' {
+			padding = append(padding, '\t')
+		} else {
+			padding = append(padding, ' ')
+		}
+	}
+
+	return string(padding)
+}
+
 // Publish prints a compact report to the configured output.
 func (tr CompactReporter) Publish(_ context.Context, r report.Report) error {
 	if len(r.Violations) == 0 {
@@ -288,7 +583,7 @@ func (tr GitHubReporter) Publish(ctx context.Context, r report.Report) error {
 	for _, violation := range r.Violations {
 		_, err := fmt.Fprintf(tr.out,
 			"::%s file=%s,line=%d,col=%d::%s\n",
-			violation.Level,
+			githubCommand(violation.Level),
 			violation.Location.File,
 			violation.Location.Row,
 			violation.Location.Column,
@@ -350,6 +645,17 @@ func (tr GitHubReporter) Publish(ctx context.Context, r report.Report) error {
 	return nil
 }
 
+// githubCommand returns the workflow command used to annotate violations of level, which is either
+// error, warning or notice.
+func githubCommand(level string) string {
+	switch level {
+	case config.LevelInfo, config.LevelHint:
+		return "notice"
+	default:
+		return level
+	}
+}
+
 // Publish prints a SARIF report to the configured output.
 func (tr SarifReporter) Publish(_ context.Context, r report.Report) error {
 	rep, err := sarif.New(sarif.Version210)
@@ -371,7 +677,7 @@ func (tr SarifReporter) Publish(_ context.Context, r report.Report) error {
 		run.AddDistinctArtifact(violation.Location.File)
 
 		run.CreateResultForRule(violation.Title).
-			WithLevel(violation.Level).
+			WithLevel(sarifLevel(violation.Level)).
 			WithMessage(sarif.NewTextMessage(violation.Description)).
 			AddLocation(getLocation(violation))
 	}
@@ -401,6 +707,19 @@ func (tr SarifReporter) Publish(_ context.Context, r report.Report) error {
 	return rep.PrettyWrite(tr.out)
 }
 
+// sarifLevel maps the level of a violation to one of the levels known to SARIF, which are error, warning,
+// note and none.
+func sarifLevel(level string) string {
+	switch level {
+	case config.LevelInfo:
+		return "note"
+	case config.LevelHint:
+		return "none"
+	default:
+		return level
+	}
+}
+
 func getLocation(violation report.Violation) *sarif.Location {
 	physicalLocation := sarif.NewPhysicalLocation().
 		WithArtifactLocation(
@@ -452,7 +771,10 @@ func (tr JUnitReporter) Publish(_ context.Context, r report.Report) error {
 	violationsPerFile := map[string][]report.Violation{}
 
 	for _, violation := range r.Violations {
-		files = append(files, violation.Location.File)
+		if _, ok := violationsPerFile[violation.Location.File]; !ok {
+			files = append(files, violation.Location.File)
+		}
+
 		violationsPerFile[violation.Location.File] = append(violationsPerFile[violation.Location.File], violation)
 	}
 
@@ -464,6 +786,11 @@ func (tr JUnitReporter) Publish(_ context.Context, r report.Report) error {
 		}
 
 		for _, violation := range violationsPerFile[file] {
+			text := ""
+			if violation.Location.Text != nil {
+				text = strings.TrimSpace(*violation.Location.Text)
+			}
+
 			testsuite.AddTestcase(junit.Testcase{
 				Name:      fmt.Sprintf("%s/%s: %s", violation.Category, violation.Title, violation.Description),
 				Classname: violation.Location.String(),
@@ -475,7 +802,7 @@ func (tr JUnitReporter) Publish(_ context.Context, r report.Report) error {
 						violation.Description,
 						violation.Category,
 						violation.Location.String(),
-						strings.TrimSpace(*violation.Location.Text),
+						text,
 						getDocumentationURL(violation)),
 				},
 			})
@@ -486,3 +813,310 @@ func (tr JUnitReporter) Publish(_ context.Context, r report.Report) error {
 
 	return testSuites.WriteXML(tr.out)
 }
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Publish prints a Checkstyle XML report to the configured output.
+func (tr CheckstyleReporter) Publish(_ context.Context, r report.Report) error {
+	// group by file & sort by file
+	files := make([]string, 0)
+	violationsPerFile := map[string][]report.Violation{}
+
+	for _, violation := range r.Violations {
+		if _, ok := violationsPerFile[violation.Location.File]; !ok {
+			files = append(files, violation.Location.File)
+		}
+
+		violationsPerFile[violation.Location.File] = append(violationsPerFile[violation.Location.File], violation)
+	}
+
+	sort.Strings(files)
+
+	cr := checkstyleReport{Version: "4.3", Files: make([]checkstyleFile, 0, len(files))}
+
+	for _, file := range files {
+		cf := checkstyleFile{Name: file}
+
+		for _, violation := range violationsPerFile[file] {
+			cf.Errors = append(cf.Errors, checkstyleError{
+				Line:     violation.Location.Row,
+				Column:   violation.Location.Column,
+				Severity: checkstyleSeverity(violation.Level),
+				Message:  violation.Description,
+				Source:   fmt.Sprintf("regal.%s.%s", violation.Category, violation.Title),
+			})
+		}
+
+		cr.Files = append(cr.Files, cf)
+	}
+
+	bs, err := xml.MarshalIndent(cr, "", "  ")
+	if err != nil {
+		return fmt.Errorf("xml marshalling of report failed: %w", err)
+	}
+
+	_, err = fmt.Fprintf(tr.out, "%s%s\n", xml.Header, bs)
+
+	return err
+}
+
+// checkstyleSeverity maps the level of a violation to one of the severities known to Checkstyle,
+// which are error, warning, info and ignore. Both info and hint are reported as info.
+func checkstyleSeverity(level string) string {
+	switch level {
+	case config.LevelError, config.LevelWarning:
+		return level
+	default:
+		return "info"
+	}
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+// Publish prints a Code Climate JSON report to the configured output.
+func (tr CodeClimateReporter) Publish(_ context.Context, r report.Report) error {
+	issues := make([]codeClimateIssue, 0, len(r.Violations))
+
+	// number of times the same violation has been seen on identical lines of the same file,
+	// used to keep the fingerprints of such violations unique
+	occurrences := make(map[string]int)
+
+	for _, violation := range r.Violations {
+		begin := max(violation.Location.Row, 1)
+		end := begin
+
+		if violation.Location.End != nil && violation.Location.End.Row > begin {
+			end = violation.Location.End.Row
+		}
+
+		fingerprint := codeClimateFingerprint(violation)
+		occurrences[fingerprint]++
+
+		if n := occurrences[fingerprint]; n > 1 {
+			fingerprint = codeClimateFingerprint(violation, strconv.Itoa(n))
+		}
+
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   fmt.Sprintf("regal/%s/%s", violation.Category, violation.Title),
+			Description: violation.Description,
+			Categories:  []string{codeClimateCategory(violation.Category)},
+			Severity:    codeClimateSeverity(violation.Level),
+			Fingerprint: fingerprint,
+			Location: codeClimateLocation{
+				Path:  violation.Location.File,
+				Lines: codeClimateLines{Begin: begin, End: end},
+			},
+		})
+	}
+
+	bs, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshalling of report failed: %w", err)
+	}
+
+	_, err = fmt.Fprintln(tr.out, string(bs))
+
+	return err
+}
+
+// codeClimateFingerprint returns a fingerprint identifying a violation between runs. The row and column of the
+// violation are deliberately left out, and the (whitespace normalized) text of the line is used in their place,
+// so that the fingerprint remains the same when unrelated lines are added or removed above the violation.
+func codeClimateFingerprint(violation report.Violation, extra ...string) string {
+	text := ""
+	if violation.Location.Text != nil {
+		text = strings.Join(strings.Fields(*violation.Location.Text), " ")
+	}
+
+	h := sha256.New()
+
+	for _, v := range append([]string{violation.Location.File, violation.Category, violation.Title, text}, extra...) {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// codeClimateCategory maps the category of a violation to one of the categories known to Code Climate.
+func codeClimateCategory(category string) string {
+	switch category {
+	case "bugs", "testing":
+		return "Bug Risk"
+	case "imports":
+		return "Clarity"
+	case "performance":
+		return "Performance"
+	default:
+		return "Style"
+	}
+}
+
+// codeClimateSeverity maps the level of a violation to one of the severities known to Code Climate, which
+// are info, minor, major, critical and blocker.
+func codeClimateSeverity(level string) string {
+	switch level {
+	case config.LevelError:
+		return "major"
+	case config.LevelWarning:
+		return "minor"
+	default:
+		return "info"
+	}
+}
+
+type htmlReport struct {
+	Report     report.Report
+	Levels     []string
+	Categories []htmlCategory
+	Violations []htmlViolation
+	Notices    []report.Notice
+	Profile    []htmlProfileEntry
+}
+
+type htmlCategory struct {
+	Category string
+	Counts   map[string]int
+	Total    int
+}
+
+type htmlViolation struct {
+	report.Violation
+	SortKey string
+	Snippet []htmlSnippetLine
+}
+
+type htmlSnippetLine struct {
+	Number    int
+	Text      string
+	Highlight bool
+}
+
+type htmlProfileEntry struct {
+	report.ProfileEntry
+	Time string
+}
+
+// Publish prints an HTML report to the configured output.
+func (tr HTMLReporter) Publish(_ context.Context, r report.Report) error {
+	tpl, err := template.ParseFS(embeds.EmbedTemplatesFS, "templates/reporter/report.html.tpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML report template: %w", err)
+	}
+
+	data := htmlReport{
+		Report:     r,
+		Levels:     []string{config.LevelError, config.LevelWarning},
+		Violations: make([]htmlViolation, 0, len(r.Violations)),
+	}
+
+	categories := make(map[string]*htmlCategory)
+	sources := make(map[string][]string)
+
+	for _, violation := range r.Violations {
+		if !slices.Contains(data.Levels, violation.Level) {
+			data.Levels = append(data.Levels, violation.Level)
+		}
+
+		c, ok := categories[violation.Category]
+		if !ok {
+			c = &htmlCategory{Category: violation.Category, Counts: make(map[string]int)}
+			categories[violation.Category] = c
+		}
+
+		c.Counts[violation.Level]++
+		c.Total++
+
+		data.Violations = append(data.Violations, htmlViolation{
+			Violation: violation,
+			SortKey: fmt.Sprintf(
+				"%s:%08d:%08d", violation.Location.File, violation.Location.Row, violation.Location.Column,
+			),
+			Snippet: htmlSnippet(violation.Location, sourceLines(sources, violation.Location)),
+		})
+	}
+
+	sort.SliceStable(data.Levels, func(i, j int) bool {
+		return !config.IsMoreSevereOrEqual(data.Levels[j], data.Levels[i])
+	})
+
+	for _, name := range util.Keys(categories) {
+		data.Categories = append(data.Categories, *categories[name])
+	}
+
+	sort.Slice(data.Categories, func(i, j int) bool {
+		return data.Categories[i].Category < data.Categories[j].Category
+	})
+
+	for _, notice := range r.Notices {
+		if notice.Severity != "none" {
+			data.Notices = append(data.Notices, notice)
+		}
+	}
+
+	for _, entry := range r.Profile {
+		data.Profile = append(data.Profile, htmlProfileEntry{
+			ProfileEntry: entry,
+			Time:         (time.Duration(entry.TotalTimeNs) * time.Nanosecond).String(),
+		})
+	}
+
+	return tpl.Execute(tr.out, data)
+}
+
+// htmlSnippet returns the lines of a location along with the lines surrounding them, like in code frames, with
+// each line numbered and the lines of the location highlighted.
+func htmlSnippet(location report.Location, lines []string) []htmlSnippetLine {
+	first, endRow, last, ok := codeFrameRows(location, lines)
+	if !ok {
+		return nil
+	}
+
+	snippet := make([]htmlSnippetLine, 0, last-first+1)
+
+	for row := first; row <= last; row++ {
+		snippet = append(snippet, htmlSnippetLine{
+			Number:    row,
+			Text:      lines[row-1],
+			Highlight: row >= location.Row && row <= endRow,
+		})
+	}
+
+	return snippet
+}
@@ -3,6 +3,10 @@ package reporter
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -197,6 +201,142 @@ func TestPrettyReporterPublishNoViolations(t *testing.T) {
 	}
 }
 
+func TestPrettyReporterPublishBudgets(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := NewPrettyReporter(&buf).Publish(context.Background(), report.Report{
+		Summary: report.Summary{
+			FilesScanned: 2,
+			Budgets: []report.Budget{
+				{Scope: "global", Level: "warning", Limit: 10, Count: 0},
+				{Scope: "category", Name: "style", Level: "warning", Limit: 2, Count: 0},
+				{Scope: "rule", Name: "style/line-length", Limit: 0, Count: 0},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `2 files linted. No violations found.
+Violation budgets:
+- all warnings: 0 of 10 allowed, 10 remaining
+- style warnings: 0 of 2 allowed, 2 remaining
+- style/line-length: 0 of 0 allowed, 0 remaining
+
+`
+
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
+
+func TestPrettyReporterPublishCodeFramesGroupedByFile(t *testing.T) {
+	t.Parallel()
+
+	policy := "package p\n\nimport rego.v1\n\nallow if {\n\tinput.x == 1\n}\n\nfooBar := 1\n"
+	file := filepath.Join(t.TempDir(), "p.rego")
+
+	if err := os.WriteFile(file, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	framesRep := report.Report{
+		Summary: report.Summary{FilesScanned: 1, NumViolations: 2, FilesFailed: 1},
+		Violations: []report.Violation{
+			{
+				Title:       "prefer-snake-case",
+				Description: "Prefer snake_case for names",
+				Category:    "style",
+				Level:       "error",
+				Location:    report.Location{File: file, Row: 9, Column: 1, Text: ptr("fooBar := 1")},
+			},
+			{
+				Title:       "some-rule",
+				Description: "Some description",
+				Category:    "bugs",
+				Level:       "warning",
+				Location: report.Location{
+					File: file, Row: 6, Column: 2, Text: ptr("\tinput.x == 1"), End: &report.Position{Row: 6, Column: 9},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err := NewPrettyReporter(&buf).WithCodeFrames(true).WithGroupBy(GroupByFile).Publish(context.Background(), framesRep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectLines := strings.Split(file+` (2)
+
+Rule:         	some-rule
+Description:  	Some description
+Category:     	bugs
+Location:     	`+file+`:6:2
+Text:         	input.x == 1
+Documentation:
+
+  4 | 
+  5 | allow if {
+> 6 | 	input.x == 1
+    | 	^^^^^^^
+  7 | }
+
+Rule:         	prefer-snake-case
+Description:  	Prefer snake_case for names
+Category:     	style
+Location:     	`+file+`:9:1
+Text:         	fooBar := 1
+Documentation:
+
+  7 | }
+  8 | 
+> 9 | fooBar := 1
+    | ^^^^^^^^^^^
+
+1 file linted. 2 violations found.
+`, "\n")
+
+	actualLines := strings.Split(buf.String(), "\n")
+	if len(actualLines) != len(expectLines) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(expectLines), len(actualLines), buf.String())
+	}
+
+	for i, line := range actualLines {
+		if strings.TrimRight(line, " \t") != strings.TrimRight(expectLines[i], " \t") {
+			t.Errorf("line %d: expected %q, got %q", i+1, expectLines[i], line)
+		}
+	}
+}
+
+func TestBuildCodeFrameMultipleLines(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"package p", "", "x := {", "  \"a\": 1,", "}"}
+	violation := report.Violation{
+		Location: report.Location{Row: 3, Column: 6, End: &report.Position{Row: 5, Column: 2}},
+	}
+
+	expect := `  1 | package p
+  2 | 
+> 3 | x := {
+    |      ^
+> 4 |   "a": 1,
+    |   ^^^^^^^
+> 5 | }
+    | ^
+`
+
+	if frame := buildCodeFrame(violation, lines); frame != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, frame)
+	}
+}
+
 func TestCompactReporterPublish(t *testing.T) {
 	t.Parallel()
 
@@ -399,6 +539,37 @@ func TestGitHubReporterPublishNoViolations(t *testing.T) {
 	}
 }
 
+func TestLevelMappings(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		level, github, sarif, checkstyle, codeClimate string
+	}{
+		{"error", "error", "error", "error", "major"},
+		{"warning", "warning", "warning", "warning", "minor"},
+		{"info", "notice", "note", "info", "info"},
+		{"hint", "notice", "none", "info", "info"},
+	}
+
+	for _, tc := range cases {
+		if got := githubCommand(tc.level); got != tc.github {
+			t.Errorf("expected GitHub command %s for level %s, got %s", tc.github, tc.level, got)
+		}
+
+		if got := sarifLevel(tc.level); got != tc.sarif {
+			t.Errorf("expected SARIF level %s for level %s, got %s", tc.sarif, tc.level, got)
+		}
+
+		if got := checkstyleSeverity(tc.level); got != tc.checkstyle {
+			t.Errorf("expected Checkstyle severity %s for level %s, got %s", tc.checkstyle, tc.level, got)
+		}
+
+		if got := codeClimateSeverity(tc.level); got != tc.codeClimate {
+			t.Errorf("expected Code Climate severity %s for level %s, got %s", tc.codeClimate, tc.level, got)
+		}
+	}
+}
+
 func TestSarifReporterPublish(t *testing.T) {
 	t.Parallel()
 
@@ -697,6 +868,30 @@ Documentation: https://example.com/questionable]]></failure>
 	}
 }
 
+func TestJUnitReporterPublishSameFileWithoutText(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	violation := report.Violation{
+		Title:    "unresolved-import",
+		Category: "imports",
+		Level:    "error",
+		Location: report.Location{File: "a.rego"},
+	}
+
+	err := NewJUnitReporter(&buf).Publish(context.Background(), report.Report{
+		Violations: []report.Violation{violation, violation},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(buf.String(), "<testsuite "); n != 1 {
+		t.Errorf("expected violations in the same file to be reported in a single testsuite, got %d", n)
+	}
+}
+
 func TestJUnitReporterPublishNoViolations(t *testing.T) {
 	t.Parallel()
 
@@ -716,3 +911,257 @@ func TestJUnitReporterPublishNoViolations(t *testing.T) {
 		t.Errorf("expected \n%s, got \n%s", expect, buf.String())
 	}
 }
+
+//nolint:lll // the expected output is unfortunately longer than the allowed max line length
+func TestCheckstyleReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cr := NewCheckstyleReporter(&buf)
+
+	err := cr.Publish(context.Background(), rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a.rego">
+    <error line="1" column="1" severity="error" message="Rego must not break the law!" source="regal.legal.breaking-the-law"></error>
+  </file>
+  <file name="b.rego">
+    <error line="22" column="18" severity="warning" message="Questionable decision found" source="regal.really?.questionable-decision"></error>
+  </file>
+</checkstyle>
+`
+
+	if buf.String() != expect {
+		t.Errorf("expected \n%s, got \n%s", expect, buf.String())
+	}
+}
+
+func TestCheckstyleReporterPublishNoViolations(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cr := NewCheckstyleReporter(&buf)
+
+	err := cr.Publish(context.Background(), report.Report{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3"></checkstyle>
+`
+
+	if buf.String() != expect {
+		t.Errorf("expected \n%s, got \n%s", expect, buf.String())
+	}
+}
+
+func TestCodeClimateReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cr := NewCodeClimateReporter(&buf)
+
+	err := cr.Publish(context.Background(), rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []map[string]any
+
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatalf("failed to unmarshal output: %v\n%s", err, buf.String())
+	}
+
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+
+	first := issues[0]
+
+	if first["check_name"] != "regal/legal/breaking-the-law" {
+		t.Errorf("expected check_name regal/legal/breaking-the-law, got %v", first["check_name"])
+	}
+
+	if first["severity"] != "major" || issues[1]["severity"] != "minor" {
+		t.Errorf("expected severities major and minor, got %v and %v", first["severity"], issues[1]["severity"])
+	}
+
+	location, ok := first["location"].(map[string]any)
+	if !ok || location["path"] != "a.rego" {
+		t.Fatalf("expected location with path a.rego, got %v", first["location"])
+	}
+
+	lines, ok := location["lines"].(map[string]any)
+	if !ok || lines["begin"] != float64(1) || lines["end"] != float64(1) {
+		t.Errorf("expected lines 1-1, got %v", location["lines"])
+	}
+}
+
+func TestCodeClimateReporterPublishNoViolations(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cr := NewCodeClimateReporter(&buf)
+
+	err := cr.Publish(context.Background(), report.Report{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+}
+
+func TestCodeClimateFingerprint(t *testing.T) {
+	t.Parallel()
+
+	violation := rep.Violations[1]
+
+	moved := violation
+	moved.Location.Row += 10
+	moved.Location.Text = ptr("    default allow = true")
+
+	if codeClimateFingerprint(violation) != codeClimateFingerprint(moved) {
+		t.Error("expected fingerprint to remain the same when violation moved to another line")
+	}
+
+	other := violation
+	other.Location.Text = ptr("default allow = false")
+
+	if codeClimateFingerprint(violation) == codeClimateFingerprint(other) {
+		t.Error("expected fingerprint to change with the text of the line")
+	}
+
+	var buf bytes.Buffer
+
+	// the same violation on identical lines must still get unique fingerprints
+	err := NewCodeClimateReporter(&buf).Publish(context.Background(), report.Report{
+		Violations: []report.Violation{violation, moved},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []codeClimateIssue
+
+	if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Error("expected duplicate violations to have unique fingerprints")
+	}
+}
+
+func TestHTMLReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	htmlRep := rep
+	htmlRep.Violations = append(slices.Clone(rep.Violations), report.Violation{
+		Title:       "xss",
+		Description: "<script>alert(1)</script>",
+		Category:    "legal",
+		Level:       "warning",
+		Location:    report.Location{File: "c.rego", Row: 3, Column: 1, Text: ptr("x := 1")},
+	})
+	htmlRep.Profile = []report.ProfileEntry{
+		{Location: "a.rego:1", TotalTimeNs: 1500, NumEval: 2, NumRedo: 1},
+	}
+
+	var buf bytes.Buffer
+
+	err := NewHTMLReporter(&buf).Publish(context.Background(), htmlRep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+
+	expected := []string{
+		// summary per category and level
+		"<td>legal</td>\n      <td class=\"num\">1</td>\n      <td class=\"num\">1</td>\n      <td class=\"num\">2</td>",
+		`<td>a.rego:1:1</td>`,
+		`<span class="gutter">22</span>default allow = true`,
+		`<a href="https://example.com/illegal">documentation</a>`,
+		`<li><strong>some-category/rule-missing-capability</strong>: Rule missing capability bar</li>`,
+		`&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`<h2>Profile</h2>`,
+		`<td class="num">1.5µs</td>`,
+	}
+
+	for _, exp := range expected {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q", exp)
+		}
+	}
+
+	if strings.Contains(out, "rule-made-obsolete") {
+		t.Error("expected notices with severity none to be left out")
+	}
+}
+
+func TestHTMLReporterPublishSnippetFromFile(t *testing.T) {
+	t.Parallel()
+
+	policy := "package p\n\nimport rego.v1\n\nallow if {\n\tinput.x == 1\n}\n\nfooBar := 1\n"
+	file := filepath.Join(t.TempDir(), "p.rego")
+
+	if err := os.WriteFile(file, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	htmlRep := report.Report{
+		Violations: []report.Violation{{
+			Title:    "some-rule",
+			Category: "bugs",
+			Level:    "warning",
+			Location: report.Location{File: file, Row: 6, Column: 2, Text: ptr("\tinput.x == 1")},
+		}},
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewHTMLReporter(&buf).Publish(context.Background(), htmlRep); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "<pre>" +
+		"<span><span class=\"gutter\">4</span></span>\n" +
+		"<span><span class=\"gutter\">5</span>allow if {</span>\n" +
+		"<span class=\"highlight\"><span class=\"gutter\">6</span>\tinput.x == 1</span>\n" +
+		"<span><span class=\"gutter\">7</span>}</span>\n" +
+		"</pre>"
+
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected output to contain %q, got:\n%s", expected, buf.String())
+	}
+}
+
+func TestHTMLReporterPublishNoViolations(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := NewHTMLReporter(&buf).Publish(context.Background(), report.Report{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "No violations found.") {
+		t.Errorf("expected no violations message, got %s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), "<h2>Violations</h2>") || strings.Contains(buf.String(), "<h2>Profile</h2>") {
+		t.Error("expected no violations or profile sections")
+	}
+}
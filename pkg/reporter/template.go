@@ -0,0 +1,186 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// TemplateReporter reports violations by rendering the report through a user-provided Go template
+// (https://pkg.go.dev/text/template). See TemplateFuncs for the functions available in templates.
+type TemplateReporter struct {
+	out io.Writer
+	tpl *template.Template
+}
+
+// ViolationGroup is a group of violations sharing the same key, as returned by the groupByFile
+// and groupByRule template functions.
+type ViolationGroup struct {
+	Key        string
+	Violations []report.Violation
+}
+
+// NewTemplateReporter creates a new TemplateReporter, parsing text as the template to render.
+func NewTemplateReporter(out io.Writer, name, text string) (TemplateReporter, error) {
+	tpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(text)
+	if err != nil {
+		return TemplateReporter{}, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	return TemplateReporter{out: out, tpl: tpl}, nil
+}
+
+// NewTemplateReporterFromFile creates a new TemplateReporter, using the template found in the file at path.
+func NewTemplateReporterFromFile(out io.Writer, path string) (TemplateReporter, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return TemplateReporter{}, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	return NewTemplateReporter(out, filepath.Base(path), string(bs))
+}
+
+// Publish renders the report through the template, and prints the result to the configured output.
+func (tr TemplateReporter) Publish(_ context.Context, r report.Report) error {
+	if r.Violations == nil {
+		r.Violations = []report.Violation{}
+	}
+
+	if err := tr.tpl.Execute(tr.out, r); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return nil
+}
+
+// TemplateFuncs returns the functions available in templates, in addition to those built into text/template:
+//
+//   - json: encodes any value as JSON, e.g. {{ json .Description }}
+//   - xml: escapes a string for use in XML text or attributes
+//   - relPath: returns a path relative to the current working directory
+//   - groupByFile: groups violations by file, sorted by file name
+//   - groupByRule: groups violations by rule, with keys on the form category/title, sorted by key
+//   - severity: maps a level to another value using a dict, or returns the level itself if not found in the dict,
+//     e.g. {{ severity .Level (dict "error" "critical" "warning" "minor") }}
+//   - dict: creates a map from a list of key and value pairs
+//   - docURL: returns the documentation URL of a violation
+//   - lower, upper, trim, join, replace: the string functions of the same name from the strings package
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"json":        templateJSON,
+		"xml":         templateXML,
+		"relPath":     templateRelPath,
+		"groupByFile": groupViolations(func(v report.Violation) string { return v.Location.File }),
+		"groupByRule": groupViolations(func(v report.Violation) string { return v.Category + "/" + v.Title }),
+		"severity":    templateSeverity,
+		"dict":        templateDict,
+		"docURL":      getDocumentationURL,
+		"lower":       strings.ToLower,
+		"upper":       strings.ToUpper,
+		"trim":        strings.TrimSpace,
+		"join":        strings.Join,
+		"replace":     strings.ReplaceAll,
+	}
+}
+
+func templateJSON(v any) (string, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("json marshalling failed: %w", err)
+	}
+
+	return string(bs), nil
+}
+
+func templateXML(s string) (string, error) {
+	var buf bytes.Buffer
+
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", fmt.Errorf("xml escaping failed: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+func templateRelPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+func groupViolations(key func(report.Violation) string) func([]report.Violation) []ViolationGroup {
+	return func(violations []report.Violation) []ViolationGroup {
+		groups := make([]ViolationGroup, 0)
+		index := make(map[string]int)
+
+		for _, violation := range violations {
+			k := key(violation)
+
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, ViolationGroup{Key: k})
+			}
+
+			groups[i].Violations = append(groups[i].Violations, violation)
+		}
+
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].Key < groups[j].Key
+		})
+
+		return groups
+	}
+}
+
+func templateSeverity(level string, mapping map[string]any) string {
+	if v, ok := mapping[level]; ok {
+		return fmt.Sprint(v)
+	}
+
+	return level
+}
+
+func templateDict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict requires an even number of arguments")
+	}
+
+	dict := make(map[string]any, len(pairs)/2)
+
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict keys must be strings, got %T", pairs[i])
+		}
+
+		dict[key] = pairs[i+1]
+	}
+
+	return dict, nil
+}
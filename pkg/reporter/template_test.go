@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestTemplateReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	tpl := `{{ range groupByFile .Violations -}}
+## {{ .Key }}
+{{ range .Violations -}}
+- {{ severity .Level (dict "error" "critical") }}: {{ .Category }}/{{ .Title }} {{ json .Description }} ({{ docURL . }})
+{{ end -}}
+{{ end -}}
+{{ range groupByRule .Violations }}{{ .Key }}={{ len .Violations }};{{ end }}
+<m>{{ xml "a < b & c" }}</m>
+{{ .Summary.NumViolations }} violations in {{ .Summary.FilesScanned }} files
+`
+
+	var buf bytes.Buffer
+
+	tr, err := NewTemplateReporter(&buf, "test", tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.Publish(context.Background(), rep); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `## a.rego
+- critical: legal/breaking-the-law "Rego must not break the law!" (https://example.com/illegal)
+## b.rego
+- warning: really?/questionable-decision "Questionable decision found" (https://example.com/questionable)
+legal/breaking-the-law=1;really?/questionable-decision=1;
+<m>a &lt; b &amp; c</m>
+2 violations in 3 files
+`
+
+	if buf.String() != expect {
+		t.Errorf("expected \n%s, got \n%s", expect, buf.String())
+	}
+}
+
+func TestTemplateReporterInvalidTemplate(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewTemplateReporter(&bytes.Buffer{}, "test", "{{ .Violations"); err == nil {
+		t.Error("expected error parsing invalid template")
+	}
+}
+
+func TestTemplateRelPath(t *testing.T) {
+	t.Parallel()
+
+	if got := templateRelPath("a/b.rego"); got != "a/b.rego" {
+		t.Errorf("expected a/b.rego, got %s", got)
+	}
+}
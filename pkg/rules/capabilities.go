@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+
+	"github.com/styrainc/regal/internal/docs"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// UnsupportedCapabilityRule reports calls to built-in functions, imports of future keywords and use of language
+// features not supported by the capabilities configured, i.e. the OPA version targeted. Only built-in functions
+// known to OPA are reported, as calls to unknown functions are compile errors regardless of the target.
+type UnsupportedCapabilityRule struct {
+	ruleConfig   config.Rule
+	capabilities *config.Capabilities
+}
+
+const (
+	unsupportedCapabilityTitle       = "unsupported-capability"
+	unsupportedCapabilityDescription = "Built-in function, keyword or feature not supported by target capabilities"
+	unsupportedCapabilityCategory    = "bugs"
+)
+
+func NewUnsupportedCapabilityRule(conf config.Config) *UnsupportedCapabilityRule {
+	rule := &UnsupportedCapabilityRule{
+		ruleConfig:   config.Rule{Level: "error"},
+		capabilities: conf.Capabilities,
+	}
+
+	if ruleConf, ok := conf.Rules[unsupportedCapabilityCategory][unsupportedCapabilityTitle]; ok {
+		rule.ruleConfig = ruleConf
+	}
+
+	return rule
+}
+
+func (u *UnsupportedCapabilityRule) Run(ctx context.Context, input Input) (*report.Report, error) {
+	result := &report.Report{}
+
+	// without capabilities configured, the capabilities of the current OPA version are used
+	if u.capabilities == nil {
+		return result, nil
+	}
+
+	for _, filename := range input.FileNames {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout when running %s rule: %w", unsupportedCapabilityTitle, ctx.Err())
+		default:
+		}
+
+		module := input.Modules[filename]
+
+		result.Violations = append(result.Violations, u.checkImports(module, input)...)
+		result.Violations = append(result.Violations, u.checkRuleHeads(module, input)...)
+		result.Violations = append(result.Violations, u.checkBuiltins(module, input)...)
+	}
+
+	return result, nil
+}
+
+func (u *UnsupportedCapabilityRule) checkImports(module *ast.Module, input Input) []report.Violation {
+	violations := make([]report.Violation, 0)
+	futureKeywords := ast.Ref{ast.FutureRootDocument, ast.StringTerm("keywords")}
+
+	for _, imp := range module.Imports {
+		path, ok := imp.Path.Value.(ast.Ref)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case path.Equal(ast.RegoV1CompatibleRef):
+			if !slices.Contains(u.capabilities.Features, ast.FeatureRegoV1Import) {
+				violations = append(violations, u.violation(
+					"Import of rego.v1",
+					&ast.Capabilities{Features: []string{ast.FeatureRegoV1Import}},
+					imp.Path.Location,
+					input,
+				))
+			}
+		case path.HasPrefix(futureKeywords):
+			// import future.keywords imports all future keywords
+			keywords := ast.CapabilitiesForThisVersion().FutureKeywords
+			if kw, ok := path[len(path)-1].Value.(ast.String); ok && len(path) > 2 {
+				keywords = []string{string(kw)}
+			}
+
+			missing := make([]string, 0, len(keywords))
+
+			for _, kw := range keywords {
+				if !slices.Contains(u.capabilities.FutureKeywords, kw) {
+					missing = append(missing, kw)
+				}
+			}
+
+			if len(missing) > 0 {
+				violations = append(violations, u.violation(
+					fmt.Sprintf("Future keyword %s", strings.Join(missing, ", ")),
+					&ast.Capabilities{FutureKeywords: missing},
+					imp.Path.Location,
+					input,
+				))
+			}
+		}
+	}
+
+	return violations
+}
+
+// checkRuleHeads reports rules with ref heads, which require features not present in older versions of OPA.
+// The conditions mirror those of the OPA compiler.
+func (u *UnsupportedCapabilityRule) checkRuleHeads(module *ast.Module, input Input) []report.Violation {
+	violations := make([]report.Violation, 0)
+
+	for _, rule := range module.Rules {
+		ref := rule.Head.Ref()
+
+		feature := ""
+
+		for i := 1; i < len(ref); i++ {
+			if _, ok := ref[i].Value.(ast.String); !ok && (rule.Head.RuleKind() == ast.MultiValue || i != len(ref)-1) {
+				feature = ast.FeatureRefHeads
+
+				break
+			}
+		}
+
+		if feature == "" && (rule.Head.Name == "" || len(ref) > 2) {
+			feature = ast.FeatureRefHeadStringPrefixes
+		}
+
+		if feature == "" || slices.Contains(u.capabilities.Features, feature) {
+			continue
+		}
+
+		name := "Rule head with ref"
+		if feature == ast.FeatureRefHeads {
+			name = "Rule head with general ref (containing variables)"
+		}
+
+		violations = append(violations, u.violation(
+			name,
+			&ast.Capabilities{Features: []string{feature}},
+			rule.Head.Location,
+			input,
+		))
+	}
+
+	return violations
+}
+
+func (u *UnsupportedCapabilityRule) checkBuiltins(module *ast.Module, input Input) []report.Violation {
+	violations := make([]report.Violation, 0)
+
+	// functions declared in the module take precedence over built-in functions
+	declared := make(map[string]struct{})
+
+	for _, rule := range module.Rules {
+		declared[rule.Head.Ref().String()] = struct{}{}
+	}
+
+	check := func(operator *ast.Term) {
+		name := operator.String()
+
+		if _, ok := ast.BuiltinMap[name]; !ok {
+			return
+		}
+
+		if _, ok := u.capabilities.Builtins[name]; ok {
+			return
+		}
+
+		if _, ok := declared[name]; ok {
+			return
+		}
+
+		violations = append(violations, u.violation(
+			fmt.Sprintf("Built-in function %s", name),
+			&ast.Capabilities{Builtins: []*ast.Builtin{{Name: name}}},
+			operator.Location,
+			input,
+		))
+	}
+
+	ast.WalkExprs(module, func(expr *ast.Expr) bool {
+		if expr.IsCall() {
+			check(expr.Terms.([]*ast.Term)[0])
+		}
+
+		return false
+	})
+
+	ast.WalkTerms(module, func(term *ast.Term) bool {
+		if call, ok := term.Value.(ast.Call); ok && len(call) > 0 {
+			check(call[0])
+		}
+
+		return false
+	})
+
+	return violations
+}
+
+// violation returns a violation for the subject described, naming the first version of OPA where the capabilities
+// required are supported, when known.
+func (u *UnsupportedCapabilityRule) violation(
+	subject string,
+	required *ast.Capabilities,
+	loc *ast.Location,
+	input Input,
+) report.Violation {
+	description := subject + " not supported by target capabilities"
+
+	if version, ok := required.MinimumCompatibleVersion(); ok {
+		description += fmt.Sprintf(", requires OPA v%s or later", version)
+	}
+
+	return violationAt(u, description, loc, input)
+}
+
+func (*UnsupportedCapabilityRule) Name() string {
+	return unsupportedCapabilityTitle
+}
+
+func (*UnsupportedCapabilityRule) Category() string {
+	return unsupportedCapabilityCategory
+}
+
+func (*UnsupportedCapabilityRule) Description() string {
+	return unsupportedCapabilityDescription
+}
+
+func (*UnsupportedCapabilityRule) Documentation() string {
+	return docs.CreateDocsURL(unsupportedCapabilityCategory, unsupportedCapabilityTitle)
+}
+
+func (u *UnsupportedCapabilityRule) Config() config.Rule {
+	return u.ruleConfig
+}
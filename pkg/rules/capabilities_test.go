@@ -0,0 +1,89 @@
+package rules_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/styrainc/regal/internal/testutil"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/rules"
+)
+
+func TestUnsupportedCapabilityRule(t *testing.T) {
+	t.Parallel()
+
+	var conf config.Config
+
+	err := yaml.Unmarshal([]byte("rules: {}\ncapabilities:\n  from:\n    engine: opa\n    version: v0.41.0\n"), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := inputPolicies(t, map[string]string{"p.rego": `package p
+
+import future.keywords.if
+import future.keywords.in
+
+keys := object.keys(input.obj)
+
+n := count(strings.count(input.s, "a"))
+
+a.b.c := 1
+
+x.y := 1
+
+users[name].roles := input.roles if some name in input.names
+`,
+		"q.rego": "package q\n\nimport rego.v1\n",
+	})
+
+	result := testutil.Must(rules.NewUnsupportedCapabilityRule(conf).Run(context.Background(), input))(t)
+
+	violations := make([]string, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		violations = append(violations, violation.Location.String()+": "+violation.Description)
+	}
+
+	slices.Sort(violations)
+
+	expected := []string{
+		"p.rego:10:1: Rule head with ref not supported by target capabilities, requires OPA v0.46.0 or later",
+		"p.rego:14:1: Rule head with general ref (containing variables) not supported by target capabilities, " +
+			"requires OPA v0.59.0 or later",
+		"p.rego:3:8: Future keyword if not supported by target capabilities, requires OPA v0.42.0 or later",
+		"p.rego:6:9: Built-in function object.keys not supported by target capabilities, requires OPA v0.47.0 or later",
+		"p.rego:8:12: Built-in function strings.count not supported by target capabilities, " +
+			"requires OPA v0.67.0 or later",
+		"q.rego:3:8: Import of rego.v1 not supported by target capabilities, requires OPA v0.59.0 or later",
+	}
+
+	if !slices.Equal(violations, expected) {
+		t.Errorf("expected violations:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(violations, "\n"))
+	}
+
+	for _, violation := range result.Violations {
+		if violation.Title != "unsupported-capability" || violation.Category != "bugs" || violation.Level != "error" {
+			t.Errorf("unexpected violation %s/%s with level %s", violation.Category, violation.Title, violation.Level)
+		}
+	}
+}
+
+func TestUnsupportedCapabilityRuleCurrentCapabilities(t *testing.T) {
+	t.Parallel()
+
+	input := inputPolicies(t, map[string]string{
+		"p.rego": "package p\n\nimport rego.v1\n\nn := strings.count(input.s, \"a\")\n\na.b[x].c := x if some x in input.xs\n",
+	})
+
+	conf := config.Config{Capabilities: config.CapabilitiesForThisVersion()}
+
+	result := testutil.Must(rules.NewUnsupportedCapabilityRule(conf).Run(context.Background(), input))(t)
+
+	if len(result.Violations) != 0 {
+		t.Errorf("expected no violations, got %v", result.Violations)
+	}
+}
@@ -0,0 +1,249 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/types"
+
+	"github.com/styrainc/regal/internal/compile"
+	"github.com/styrainc/regal/internal/docs"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// CompileErrorRule reports errors found when compiling the input modules, like unsafe variables,
+// type errors and recursion, which are otherwise only found by `opa check`.
+type CompileErrorRule struct {
+	ruleConfig   config.Rule
+	capabilities *ast.Capabilities
+	// untypedBuiltins are the built-in functions declared without known types, which are not type checked
+	untypedBuiltins map[string]struct{}
+}
+
+const (
+	compileErrorTitle       = "compile-error"
+	compileErrorDescription = "Module fails to compile"
+	compileErrorCategory    = "bugs"
+)
+
+func NewCompileErrorRule(conf config.Config) *CompileErrorRule {
+	capabilities, untyped := astCapabilities(conf.Capabilities)
+
+	rule := &CompileErrorRule{
+		ruleConfig:      config.Rule{Level: "error"},
+		capabilities:    capabilities,
+		untypedBuiltins: untyped,
+	}
+
+	if ruleConf, ok := conf.Rules[compileErrorCategory][compileErrorTitle]; ok {
+		rule.ruleConfig = ruleConf
+	}
+
+	return rule
+}
+
+func (c *CompileErrorRule) Run(ctx context.Context, input Input) (*report.Report, error) {
+	result := &report.Report{}
+
+	if len(input.FileNames) == 0 {
+		return result, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout when running %s rule: %w", compileErrorTitle, ctx.Err())
+	default:
+	}
+
+	compiler := compileInput(input, ast.NewCompiler().WithCapabilities(c.capabilities))
+
+	for _, err := range compiler.Errors {
+		if isUndefinedDataFunction(err) || c.isUntypedBuiltinError(err) || c.isUnsupportedBuiltinError(err) {
+			continue
+		}
+
+		result.Violations = append(result.Violations, violationFromError(c, err, input))
+	}
+
+	return result, nil
+}
+
+// compileInput compiles the modules of input using compiler, and returns the compiler for its errors to be
+// inspected. The modules are copied first, as the compiler modifies the modules it compiles.
+func compileInput(input Input, compiler *ast.Compiler) *ast.Compiler {
+	modules := make(map[string]*ast.Module, len(input.FileNames))
+
+	for _, filename := range input.FileNames {
+		modules[filename] = input.Modules[filename].Copy()
+	}
+
+	compiler.SetErrorLimit(0)
+	compiler.Compile(modules)
+
+	return compiler
+}
+
+// isUndefinedDataFunction returns true for errors about calls to functions not found in the input modules.
+// As the input is not necessarily all modules of a project (like when linting a single file), these may
+// well be defined elsewhere, and are therefore not reported.
+func isUndefinedDataFunction(err *ast.Error) bool {
+	return err.Code == ast.TypeErr && strings.HasPrefix(err.Message, "undefined function data.")
+}
+
+// isUnsupportedBuiltinError returns true for errors about calls to built-in functions known to OPA, but not found in
+// the capabilities configured, as these are reported by the unsupported-capability rule along with the OPA version
+// required.
+func (c *CompileErrorRule) isUnsupportedBuiltinError(err *ast.Error) bool {
+	if err.Code != ast.TypeErr {
+		return false
+	}
+
+	name, ok := strings.CutPrefix(err.Message, "undefined function ")
+	if !ok {
+		return false
+	}
+
+	if _, known := ast.BuiltinMap[name]; !known {
+		return false
+	}
+
+	return !slices.ContainsFunc(c.capabilities.Builtins, func(builtin *ast.Builtin) bool {
+		return builtin.Name == name
+	})
+}
+
+// isUntypedBuiltinError returns true for type errors about calls to built-in functions declared without types,
+// like arity mismatches, as the declaration used for these functions is not the real one.
+func (c *CompileErrorRule) isUntypedBuiltinError(err *ast.Error) bool {
+	if err.Code != ast.TypeErr {
+		return false
+	}
+
+	name, _, ok := strings.Cut(err.Message, ":")
+
+	_, untyped := c.untypedBuiltins[name]
+
+	return ok && untyped
+}
+
+// violationFromError returns a violation of rule for an error reported by the compiler.
+func violationFromError(rule Rule, err *ast.Error, input Input) report.Violation {
+	return violationAt(rule, fmt.Sprintf("%s: %s", err.Code, err.Message), err.Location, input)
+}
+
+// violationAt returns a violation of rule with description at the location of an AST node, if not nil.
+func violationAt(rule Rule, description string, loc *ast.Location, input Input) report.Violation {
+	violation := report.Violation{
+		Title:       rule.Name(),
+		Description: description,
+		Category:    rule.Category(),
+		RelatedResources: []report.RelatedResource{{
+			Description: relatedResourcesDescription,
+			Reference:   rule.Documentation(),
+		}},
+		Level: rule.Config().Level,
+	}
+
+	if loc == nil {
+		return violation
+	}
+
+	violation.Location = report.Location{
+		File:   loc.File,
+		Row:    loc.Row,
+		Column: loc.Col,
+	}
+
+	// the text of the location is only the erroneous part, so the end is derived from that,
+	// while the text reported is the whole line, as with other rules
+	if len(loc.Text) > 0 {
+		lines := strings.Split(string(loc.Text), "\n")
+		end := &report.Position{Row: loc.Row + len(lines) - 1, Column: len(lines[len(lines)-1]) + 1}
+
+		if len(lines) == 1 {
+			end.Column += loc.Col - 1
+		}
+
+		violation.Location.End = end
+	}
+
+	if lines := strings.Split(input.FileContent[loc.File], "\n"); loc.Row > 0 && loc.Row <= len(lines) {
+		violation.Location.Text = &lines[loc.Row-1]
+	}
+
+	return violation
+}
+
+// astCapabilities returns the capabilities configured as OPA capabilities, with the Regal built-in functions added
+// for the sake of custom rules. As the configuration doesn't keep the types of built-in functions, those not known to
+// the current version of OPA are declared with arguments and result of any type, and returned as untyped.
+func astCapabilities(caps *config.Capabilities) (*ast.Capabilities, map[string]struct{}) {
+	untyped := make(map[string]struct{})
+
+	if caps == nil {
+		return compile.Capabilities(), untyped
+	}
+
+	result := &ast.Capabilities{
+		FutureKeywords: caps.FutureKeywords,
+		Features:       caps.Features,
+	}
+
+	names := make([]string, 0, len(caps.Builtins))
+	for name := range caps.Builtins {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if builtin, ok := ast.BuiltinMap[name]; ok {
+			result.Builtins = append(result.Builtins, builtin)
+
+			continue
+		}
+
+		args := make([]types.Type, len(caps.Builtins[name].Decl.Args))
+		for i := range args {
+			args[i] = types.A
+		}
+
+		result.Builtins = append(result.Builtins, &ast.Builtin{Name: name, Decl: types.NewFunction(args, types.A)})
+		untyped[name] = struct{}{}
+	}
+
+	for _, builtin := range compile.Capabilities().Builtins {
+		if _, ok := caps.Builtins[builtin.Name]; !ok && strings.HasPrefix(builtin.Name, "regal.") {
+			result.Builtins = append(result.Builtins, builtin)
+		}
+	}
+
+	return result, untyped
+}
+
+func (*CompileErrorRule) Name() string {
+	return compileErrorTitle
+}
+
+func (*CompileErrorRule) Category() string {
+	return compileErrorCategory
+}
+
+func (*CompileErrorRule) Description() string {
+	return compileErrorDescription
+}
+
+func (*CompileErrorRule) Documentation() string {
+	return docs.CreateDocsURL(compileErrorCategory, compileErrorTitle)
+}
+
+func (c *CompileErrorRule) Config() config.Rule {
+	return c.ruleConfig
+}
+
+func (*CompileErrorRule) programRule() {}
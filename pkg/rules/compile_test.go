@@ -0,0 +1,170 @@
+package rules_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"gopkg.in/yaml.v3"
+
+	"github.com/styrainc/regal/internal/parse"
+	"github.com/styrainc/regal/internal/testutil"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+	"github.com/styrainc/regal/pkg/rules"
+)
+
+func TestCompileErrorRule(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		policies    map[string]string
+		count       int
+		description string
+		location    report.Location
+	}{
+		"unsafe var": {
+			policies:    map[string]string{"p.rego": "package p\n\nimport rego.v1\n\nallow if {\n\tinput.x == unknown\n}\n"},
+			count:       1,
+			description: "rego_unsafe_var_error: var unknown is unsafe",
+			location:    report.Location{File: "p.rego", Row: 6, Column: 2, End: &report.Position{Row: 6, Column: 20}},
+		},
+		"type error": {
+			policies:    map[string]string{"p.rego": "package p\n\nimport rego.v1\n\nn := count(1)\n"},
+			count:       1,
+			description: "rego_type_error: count: invalid argument(s)",
+			location:    report.Location{File: "p.rego", Row: 5, Column: 6, End: &report.Position{Row: 5, Column: 14}},
+		},
+		"recursion across files": {
+			policies: map[string]string{
+				"a.rego": "package a\n\nimport rego.v1\n\nx if data.b.y\n",
+				"b.rego": "package b\n\nimport rego.v1\n\ny if data.a.x\n",
+			},
+			// reported once for each rule in the cycle
+			count:       2,
+			description: "rego_recursion_error: rule data.a.x is recursive: data.a.x -> data.b.y -> data.a.x",
+			location:    report.Location{File: "a.rego", Row: 5, Column: 1, End: &report.Position{Row: 5, Column: 14}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			input := inputPolicies(t, tc.policies)
+			result := testutil.Must(rules.NewCompileErrorRule(config.Config{}).Run(context.Background(), input))(t)
+
+			if len(result.Violations) != tc.count {
+				t.Fatalf("expected %d violation(s), got %v", tc.count, result.Violations)
+			}
+
+			i := slices.IndexFunc(result.Violations, func(v report.Violation) bool {
+				return v.Location.File == tc.location.File
+			})
+			if i == -1 {
+				t.Fatalf("expected violation in %s, got %v", tc.location.File, result.Violations)
+			}
+
+			violation := result.Violations[i]
+
+			if violation.Title != "compile-error" || violation.Category != "bugs" || violation.Level != "error" {
+				t.Errorf("unexpected violation %s/%s with level %s", violation.Category, violation.Title, violation.Level)
+			}
+
+			if violation.Description != tc.description {
+				t.Errorf("expected description %q, got %q", tc.description, violation.Description)
+			}
+
+			loc := violation.Location
+			if loc.File != tc.location.File || loc.Row != tc.location.Row || loc.Column != tc.location.Column {
+				t.Errorf("expected location %s, got %s", tc.location.String(), loc.String())
+			}
+
+			if loc.End == nil || *loc.End != *tc.location.End {
+				t.Errorf("expected end %v, got %v", tc.location.End, loc.End)
+			}
+
+			if loc.Text == nil {
+				t.Error("expected text to be set")
+			}
+		})
+	}
+}
+
+func TestCompileErrorRuleNoViolations(t *testing.T) {
+	t.Parallel()
+
+	// functions in packages not provided may well be defined elsewhere
+	input := inputPolicies(t, map[string]string{
+		"p.rego": "package p\n\nimport rego.v1\n\nallow if data.lib.check(input.x)\n\nmeta := regal.last([1, 2])\n",
+	})
+
+	result := testutil.Must(rules.NewCompileErrorRule(config.Config{}).Run(context.Background(), input))(t)
+
+	if len(result.Violations) != 0 {
+		t.Errorf("expected no violations, got %v", result.Violations)
+	}
+}
+
+func TestCompileErrorRuleCapabilities(t *testing.T) {
+	t.Parallel()
+
+	var conf config.Config
+
+	err := yaml.Unmarshal([]byte(`rules: {}
+capabilities:
+  minus:
+    builtins:
+      - name: http.send
+  plus:
+    builtins:
+      - name: ldap.query
+        type: function
+        decl:
+          args:
+            - type: string
+          result:
+            type: object
+`), &conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := inputPolicies(t, map[string]string{
+		"p.rego": `package p
+
+import rego.v1
+
+user := ldap.query(input.name)
+
+resp := http.send({"method": "get", "url": "https://example.com"})
+
+other := no.such.function(input.name)
+`,
+	})
+
+	result := testutil.Must(rules.NewCompileErrorRule(conf).Run(context.Background(), input))(t)
+
+	// built-in functions known to OPA, but not found in the capabilities, are left for
+	// the unsupported-capability rule to report
+	if len(result.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %v", result.Violations)
+	}
+
+	if exp, got := "rego_type_error: undefined function no.such.function", result.Violations[0].Description; exp != got {
+		t.Errorf("expected description %q, got %q", exp, got)
+	}
+}
+
+func inputPolicies(t *testing.T, policies map[string]string) rules.Input {
+	t.Helper()
+
+	modules := make(map[string]*ast.Module, len(policies))
+
+	for filename, policy := range policies {
+		modules[filename] = testutil.Must(parse.Module(filename, policy))(t)
+	}
+
+	return rules.NewInput(policies, modules)
+}
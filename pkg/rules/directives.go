@@ -0,0 +1,186 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/styrainc/regal/internal/docs"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// UnusedIgnoreDirectiveRule reports ignore directives that suppress no violations, reference rules not known to
+// Regal, or have an invalid until date. Whether a directive suppressed any violations is known only once all other
+// rules have run, so Run reports nothing, and the linter calls Report with the violations suppressed instead.
+type UnusedIgnoreDirectiveRule struct {
+	ruleConfig config.Rule
+}
+
+const (
+	unusedIgnoreDirectiveTitle       = "unused-ignore-directive"
+	unusedIgnoreDirectiveDescription = "Unused ignore directive"
+	unusedIgnoreDirectiveCategory    = "style"
+
+	directiveKindIgnore     = "ignore"
+	directiveKindIgnoreFile = "ignore-file"
+	directiveKindDisable    = "disable"
+	directiveKindEnable     = "enable"
+)
+
+func NewUnusedIgnoreDirectiveRule(conf config.Config) *UnusedIgnoreDirectiveRule {
+	rule := &UnusedIgnoreDirectiveRule{ruleConfig: config.Rule{Level: "warning"}}
+
+	if ruleConf, ok := conf.Rules[unusedIgnoreDirectiveCategory][unusedIgnoreDirectiveTitle]; ok {
+		rule.ruleConfig = ruleConf
+	}
+
+	return rule
+}
+
+func (*UnusedIgnoreDirectiveRule) Run(context.Context, Input) (*report.Report, error) {
+	return &report.Report{}, nil
+}
+
+// Report returns violations for the directives of file, given the violations suppressed in the file and the rules
+// known. Directives are reported as unused only if any of the rules they reference is enabled, as told by enabled.
+func (u *UnusedIgnoreDirectiveRule) Report(
+	file string,
+	directives []report.IgnoreDirective,
+	suppressed []report.Violation,
+	known config.KnownRules,
+	enabled func(category, title string) bool,
+) []report.Violation {
+	violations := make([]report.Violation, 0)
+
+	for _, directive := range directives {
+		if directive.Until != "" {
+			if _, err := time.Parse(time.DateOnly, directive.Until); err != nil {
+				violations = append(violations, u.violation(file, directive,
+					fmt.Sprintf("Ignore directive has invalid until date %s, expected YYYY-MM-DD", directive.Until)))
+			}
+		}
+
+		for _, rule := range directive.Rules {
+			rules := matchingRules(rule, known)
+
+			switch {
+			case len(rules) == 0:
+				violations = append(violations, u.violation(file, directive,
+					"Ignore directive references unknown rule "+rule))
+			case directive.Kind == directiveKindEnable || directive.Expired || rule == unusedIgnoreDirectiveTitle ||
+				rule == unusedIgnoreDirectiveCategory+"/"+unusedIgnoreDirectiveTitle:
+				continue
+			case !slices.ContainsFunc(rules, func(r [2]string) bool { return enabled(r[0], r[1]) }):
+				continue
+			case !slices.ContainsFunc(suppressed, func(v report.Violation) bool {
+				return IgnoresViolation(rule, v) && covers(directive, directives, rule, v.Location.Row)
+			}):
+				violations = append(violations, u.violation(file, directive,
+					"Ignore directive for "+rule+" suppresses no violations"))
+			}
+		}
+	}
+
+	return violations
+}
+
+func (u *UnusedIgnoreDirectiveRule) violation(
+	file string,
+	directive report.IgnoreDirective,
+	description string,
+) report.Violation {
+	location := directive.Location
+	location.File = file
+
+	return report.Violation{
+		Title:       unusedIgnoreDirectiveTitle,
+		Description: description,
+		Category:    unusedIgnoreDirectiveCategory,
+		RelatedResources: []report.RelatedResource{{
+			Description: relatedResourcesDescription,
+			Reference:   u.Documentation(),
+		}},
+		Location: location,
+		Level:    u.ruleConfig.Level,
+	}
+}
+
+// IgnoresViolation returns true if the rule referenced by an ignore directive, either by title, by category and
+// title, or by category for all rules in it, like `style/*`, is the rule violated.
+func IgnoresViolation(rule string, violation report.Violation) bool {
+	return rule == violation.Title ||
+		rule == violation.Category+"/"+violation.Title ||
+		rule == violation.Category+"/*"
+}
+
+// matchingRules returns the category and title of the known rules referenced by rule in an ignore directive.
+func matchingRules(rule string, known config.KnownRules) [][2]string {
+	matching := make([][2]string, 0)
+
+	category, title, qualified := strings.Cut(rule, "/")
+
+	for knownCategory, titles := range known {
+		if qualified && knownCategory != category {
+			continue
+		}
+
+		for knownTitle := range titles {
+			if (qualified && (title == "*" || title == knownTitle)) || (!qualified && rule == knownTitle) {
+				matching = append(matching, [2]string{knownCategory, knownTitle})
+			}
+		}
+	}
+
+	return matching
+}
+
+// covers returns true if directive applies to rule at row, like when applied to violations by the Rego rules:
+// an ignore directive applies to its own row and the next, a disable directive from its own row up until the row
+// before an enable directive for the same rule, or the end of the file, and an ignore-file directive to all rows.
+func covers(directive report.IgnoreDirective, directives []report.IgnoreDirective, rule string, row int) bool {
+	start := directive.Location.Row
+
+	switch directive.Kind {
+	case directiveKindIgnore:
+		return row == start || row == start+1
+	case directiveKindIgnoreFile:
+		return true
+	case directiveKindDisable:
+		end := math.MaxInt
+
+		for _, other := range directives {
+			if other.Kind == directiveKindEnable && other.Location.Row > start &&
+				slices.Contains(other.Rules, rule) {
+				end = min(end, other.Location.Row)
+			}
+		}
+
+		return row >= start && row < end
+	}
+
+	return false
+}
+
+func (*UnusedIgnoreDirectiveRule) Name() string {
+	return unusedIgnoreDirectiveTitle
+}
+
+func (*UnusedIgnoreDirectiveRule) Category() string {
+	return unusedIgnoreDirectiveCategory
+}
+
+func (*UnusedIgnoreDirectiveRule) Description() string {
+	return unusedIgnoreDirectiveDescription
+}
+
+func (*UnusedIgnoreDirectiveRule) Documentation() string {
+	return docs.CreateDocsURL(unusedIgnoreDirectiveCategory, unusedIgnoreDirectiveTitle)
+}
+
+func (u *UnusedIgnoreDirectiveRule) Config() config.Rule {
+	return u.ruleConfig
+}
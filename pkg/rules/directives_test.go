@@ -0,0 +1,129 @@
+package rules_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+	"github.com/styrainc/regal/pkg/rules"
+)
+
+func TestUnusedIgnoreDirectiveRule(t *testing.T) {
+	t.Parallel()
+
+	directive := func(kind string, row int) report.IgnoreDirective {
+		return report.IgnoreDirective{
+			Kind:     kind,
+			Rules:    []string{"prefer-snake-case"},
+			Location: report.Location{Row: row, Column: 1},
+		}
+	}
+
+	violation := func(row int) report.Violation {
+		return report.Violation{
+			Title:    "prefer-snake-case",
+			Category: "style",
+			Location: report.Location{File: "p.rego", Row: row},
+		}
+	}
+
+	cases := map[string]struct {
+		directives []report.IgnoreDirective
+		suppressed []report.Violation
+		enabled    bool
+		expected   []string
+	}{
+		"used ignore directive": {
+			directives: []report.IgnoreDirective{directive("ignore", 3)},
+			suppressed: []report.Violation{violation(4)},
+			enabled:    true,
+		},
+		"unused ignore directive": {
+			directives: []report.IgnoreDirective{directive("ignore", 3)},
+			suppressed: []report.Violation{violation(8)},
+			enabled:    true,
+			expected:   []string{"3:1: Ignore directive for prefer-snake-case suppresses no violations"},
+		},
+		"unused ignore directive of disabled rule": {
+			directives: []report.IgnoreDirective{directive("ignore", 3)},
+		},
+		"used disable directive": {
+			directives: []report.IgnoreDirective{directive("disable", 3), directive("enable", 10)},
+			suppressed: []report.Violation{violation(9)},
+			enabled:    true,
+		},
+		"disable directive used only after enable directive": {
+			directives: []report.IgnoreDirective{directive("disable", 3), directive("enable", 10)},
+			suppressed: []report.Violation{violation(12)},
+			enabled:    true,
+			expected:   []string{"3:1: Ignore directive for prefer-snake-case suppresses no violations"},
+		},
+		"used ignore-file directive": {
+			directives: []report.IgnoreDirective{directive("ignore-file", 1)},
+			suppressed: []report.Violation{violation(20)},
+			enabled:    true,
+		},
+		"expired directive": {
+			directives: []report.IgnoreDirective{{
+				Kind:     "ignore",
+				Rules:    []string{"prefer-snake-case"},
+				Location: report.Location{Row: 3, Column: 1},
+				Until:    "2000-01-01",
+				Expired:  true,
+			}},
+			enabled: true,
+		},
+		"invalid until date": {
+			directives: []report.IgnoreDirective{{
+				Kind:     "ignore",
+				Rules:    []string{"prefer-snake-case"},
+				Location: report.Location{Row: 3, Column: 1},
+				Until:    "tomorrow",
+			}},
+			suppressed: []report.Violation{violation(4)},
+			enabled:    true,
+			expected:   []string{"3:1: Ignore directive has invalid until date tomorrow, expected YYYY-MM-DD"},
+		},
+		"unknown rule": {
+			directives: []report.IgnoreDirective{{
+				Kind:     "ignore",
+				Rules:    []string{"prefer-snake-cases", "style/*", "unknown/*"},
+				Location: report.Location{Row: 3, Column: 1},
+			}},
+			suppressed: []report.Violation{violation(4)},
+			enabled:    true,
+			expected: []string{
+				"3:1: Ignore directive references unknown rule prefer-snake-cases",
+				"3:1: Ignore directive references unknown rule unknown/*",
+			},
+		},
+	}
+
+	known := config.BuiltinRules()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			enabled := func(string, string) bool { return tc.enabled }
+
+			result := rules.NewUnusedIgnoreDirectiveRule(config.Config{}).
+				Report("p.rego", tc.directives, tc.suppressed, known, enabled)
+
+			violations := make([]string, 0, len(result))
+			for _, violation := range result {
+				violations = append(violations, violation.Location.String()+": "+violation.Description)
+			}
+
+			expected := make([]string, 0, len(tc.expected))
+			for _, e := range tc.expected {
+				expected = append(expected, "p.rego:"+e)
+			}
+
+			if !slices.Equal(violations, expected) {
+				t.Errorf("expected %v, got %v", expected, violations)
+			}
+		})
+	}
+}
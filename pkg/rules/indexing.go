@@ -0,0 +1,350 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+
+	"github.com/styrainc/regal/internal/docs"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// NonIndexableRule reports rule bodies that OPA's rule indexer can't exclude from evaluation, in sets of rules
+// (or functions) with the same name where other bodies can be indexed. Which bodies can be indexed is decided by
+// the rule indexer of the compiler, while the expression explaining why a body can't be indexed is found by
+// inspecting the body as written, as the compiler rewrites bodies beyond recognition.
+type NonIndexableRule struct {
+	ruleConfig   config.Rule
+	capabilities *ast.Capabilities
+}
+
+const (
+	nonIndexableRuleTitle       = "non-indexable-rule"
+	nonIndexableRuleDescription = "Rule body can't be indexed"
+	nonIndexableRuleCategory    = "performance"
+)
+
+func NewNonIndexableRule(conf config.Config) *NonIndexableRule {
+	capabilities, _ := astCapabilities(conf.Capabilities)
+
+	rule := &NonIndexableRule{
+		ruleConfig:   config.Rule{Level: "ignore"},
+		capabilities: capabilities,
+	}
+
+	if ruleConf, ok := conf.Rules[nonIndexableRuleCategory][nonIndexableRuleTitle]; ok {
+		rule.ruleConfig = ruleConf
+	}
+
+	return rule
+}
+
+// unmatchedResolver resolves every reference to a value no indexed rule compares against, so that a lookup
+// in the rule index returns only the rules that the index can't exclude, whatever the input.
+type unmatchedResolver struct{}
+
+func (unmatchedResolver) Resolve(ast.Ref) (ast.Value, error) {
+	return ast.String("\x00regal"), nil
+}
+
+func (n *NonIndexableRule) Run(ctx context.Context, input Input) (*report.Report, error) {
+	result := &report.Report{}
+
+	if len(input.FileNames) == 0 {
+		return result, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout when running %s rule: %w", nonIndexableRuleTitle, ctx.Err())
+	default:
+	}
+
+	// rule indices are only built when compilation succeeds, and compile errors are reported by compile-error
+	compiler := compileInput(input, ast.NewCompiler().WithCapabilities(n.capabilities))
+	if compiler.Failed() {
+		return result, nil
+	}
+
+	// the rules of the input modules, by location, as the compiler works on copies of the modules
+	rules := make(map[string]*ast.Rule)
+	roots := make(map[*ast.Rule]map[ast.Var]struct{})
+	paths := make(map[string]ast.Ref)
+
+	for _, filename := range input.FileNames {
+		moduleRoots := rootVars(input.Modules[filename])
+
+		for _, rule := range input.Modules[filename].Rules {
+			rules[rule.Location.String()] = rule
+			roots[rule] = moduleRoots
+		}
+
+		for _, rule := range compiler.Modules[filename].Rules {
+			path := rule.Ref().GroundPrefix()
+			paths[path.String()] = path
+		}
+	}
+
+	for _, path := range paths {
+		index := compiler.RuleIndex(path)
+		if index == nil {
+			continue
+		}
+
+		all, err := index.AllRules(unmatchedResolver{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rules for %s from rule index: %w", path, err)
+		}
+
+		unindexed, err := index.Lookup(unmatchedResolver{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up rules for %s in rule index: %w", path, err)
+		}
+
+		// only when some bodies can be indexed does a body that can't defeat the index
+		if len(unindexed.Rules) == 0 || len(unindexed.Rules) == len(all.Rules) {
+			continue
+		}
+
+		for _, compiled := range unindexed.Rules {
+			rule, ok := rules[compiled.Location.String()]
+			if !ok {
+				continue
+			}
+
+			result.Violations = append(result.Violations, n.violation(rule, roots[rule], input))
+		}
+	}
+
+	sort.Slice(result.Violations, func(i, j int) bool {
+		a, b := result.Violations[i].Location, result.Violations[j].Location
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		return a.Row < b.Row || a.Row == b.Row && a.Column < b.Column
+	})
+
+	return result, nil
+}
+
+// violation returns a violation for a rule body that can't be indexed, located at the first expression found
+// to defeat indexing, or at the head of the rule when no such expression is found.
+func (n *NonIndexableRule) violation(rule *ast.Rule, roots map[ast.Var]struct{}, input Input) report.Violation {
+	args := make(map[ast.Var]struct{}, len(rule.Head.Args))
+
+	for _, arg := range rule.Head.Args {
+		if v, ok := arg.Value.(ast.Var); ok {
+			args[v] = struct{}{}
+		}
+	}
+
+	for _, expr := range rule.Body {
+		if reason := nonIndexableReason(expr, roots, args); reason != "" {
+			return violationAt(
+				n,
+				fmt.Sprintf("%s: `%s` %s", nonIndexableRuleDescription, expr.Location.Text, reason),
+				expr.Location,
+				input,
+			)
+		}
+	}
+
+	return violationAt(
+		n,
+		nonIndexableRuleDescription+": no expression compares input, data or a function argument to a value using ==",
+		rule.Head.Location,
+		input,
+	)
+}
+
+// nonIndexableReason returns why expr can't be indexed, or an empty string if it can, or if it doesn't refer to
+// anything the indexer considers (input, data or function arguments) and so doesn't matter for indexing. The
+// conditions mirror those of the rule indexer of the OPA compiler.
+func nonIndexableReason(expr *ast.Expr, roots, args map[ast.Var]struct{}) string {
+	if _, ok := expr.Terms.(*ast.SomeDecl); ok || expr.IsEvery() {
+		return ""
+	}
+
+	operator := ""
+	if expr.IsCall() {
+		operator = expr.Operator().String()
+	}
+
+	operands := expr.Operands()
+
+	// comparisons of local variables are reported too, as those commonly refer to input or data indirectly
+	if !expr.Negated && len(expr.With) == 0 && len(operands) == 2 &&
+		(operator == ast.Equality.Name || operator == ast.Equal.Name) {
+		return equalityReason(operands[0], operands[1], roots, args)
+	}
+
+	if !refersToIndexable(expr, roots, args) {
+		return ""
+	}
+
+	switch {
+	case expr.Negated:
+		return "is negated"
+	case len(expr.With) > 0:
+		return "uses with"
+	case operator == "":
+		return "is not compared to a value using =="
+	case operator == ast.Assign.Name, operator == ast.GlobMatch.Name && len(operands) == 3:
+		return ""
+	}
+
+	if builtin, ok := ast.BuiltinMap[operator]; ok && builtin.Infix != "" {
+		return fmt.Sprintf("compares using %s, while only equality (== or =) can be indexed", builtin.Infix)
+	}
+
+	return fmt.Sprintf("calls %s, while only equality (== or =) can be indexed", operator)
+}
+
+// equalityReason returns why an equality comparison of a and b can't be indexed, if so.
+func equalityReason(a, b *ast.Term, roots, args map[ast.Var]struct{}) string {
+	for _, pair := range [][2]*ast.Term{{a, b}, {b, a}} {
+		operand, other := pair[0], pair[1]
+
+		switch value := operand.Value.(type) {
+		case ast.Var:
+			if _, ok := args[value]; ok && isIndexableValue(other) {
+				return ""
+			}
+		case ast.Ref:
+			if v, ok := value[0].Value.(ast.Var); ok {
+				if _, ok := args[v]; ok {
+					return fmt.Sprintf("references %s, while only function arguments, not values within them, "+
+						"can be indexed", value)
+				}
+			}
+
+			if !isRootRef(value, roots) {
+				continue
+			}
+
+			if !value.IsGround() {
+				return fmt.Sprintf("references %s, which contains variables", value)
+			}
+
+			if !isIndexableValue(other) {
+				return fmt.Sprintf("compares %s to %s, which isn't a constant value", value, other)
+			}
+
+			return ""
+		}
+	}
+
+	for _, operand := range []*ast.Term{a, b} {
+		var local ast.Var
+
+		switch value := operand.Value.(type) {
+		case ast.Var:
+			local = value
+		case ast.Ref:
+			local, _ = value[0].Value.(ast.Var)
+		}
+
+		_, isRoot := roots[local]
+		if _, isArg := args[local]; isArg || isRoot || local == "" || local.IsWildcard() {
+			continue
+		}
+
+		return fmt.Sprintf("compares the local variable %s, rather than input, data or a function argument", local)
+	}
+
+	return ""
+}
+
+// refersToIndexable returns true if expr refers to input, data or any of the function arguments.
+func refersToIndexable(expr *ast.Expr, roots, args map[ast.Var]struct{}) bool {
+	found := false
+
+	ast.WalkTerms(expr, func(term *ast.Term) bool {
+		switch value := term.Value.(type) {
+		case ast.Ref:
+			found = found || isRootRef(value, roots)
+		case ast.Var:
+			_, ok := args[value]
+			found = found || ok
+		}
+
+		return found
+	})
+
+	return found
+}
+
+// rootVars returns the variables referring to input or data in module, i.e. input, data and the aliases of imports.
+func rootVars(module *ast.Module) map[ast.Var]struct{} {
+	roots := map[ast.Var]struct{}{
+		ast.InputRootDocument.Value.(ast.Var):   {},
+		ast.DefaultRootDocument.Value.(ast.Var): {},
+	}
+
+	for _, imp := range module.Imports {
+		if path, ok := imp.Path.Value.(ast.Ref); ok && isRootRef(path, roots) {
+			roots[imp.Name()] = struct{}{}
+		}
+	}
+
+	return roots
+}
+
+// isRootRef returns true if ref refers to input or data, directly or through an import.
+func isRootRef(ref ast.Ref, roots map[ast.Var]struct{}) bool {
+	v, ok := ref[0].Value.(ast.Var)
+	if !ok {
+		return false
+	}
+
+	_, ok = roots[v]
+
+	return ok
+}
+
+// isIndexableValue returns true for values the indexer can compare against: scalars, variables and arrays
+// thereof.
+func isIndexableValue(term *ast.Term) bool {
+	switch value := term.Value.(type) {
+	case ast.Null, ast.Boolean, ast.Number, ast.String, ast.Var:
+		return true
+	case *ast.Array:
+		for i := range value.Len() {
+			switch value.Elem(i).Value.(type) {
+			case ast.Null, ast.Boolean, ast.Number, ast.String, ast.Var:
+			default:
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (*NonIndexableRule) Name() string {
+	return nonIndexableRuleTitle
+}
+
+func (*NonIndexableRule) Category() string {
+	return nonIndexableRuleCategory
+}
+
+func (*NonIndexableRule) Description() string {
+	return nonIndexableRuleDescription
+}
+
+func (*NonIndexableRule) Documentation() string {
+	return docs.CreateDocsURL(nonIndexableRuleCategory, nonIndexableRuleTitle)
+}
+
+func (n *NonIndexableRule) Config() config.Rule {
+	return n.ruleConfig
+}
+
+func (*NonIndexableRule) programRule() {}
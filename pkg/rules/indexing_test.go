@@ -0,0 +1,93 @@
+package rules_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/styrainc/regal/internal/testutil"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/rules"
+)
+
+func TestNonIndexableRule(t *testing.T) {
+	t.Parallel()
+
+	input := inputPolicies(t, map[string]string{"p.rego": `package p
+
+import rego.v1
+
+import input.user
+
+allow if input.method == "GET"
+
+allow if input.size > 5
+
+allow if {
+	some u in input.users
+	u.name == "bob"
+}
+
+allow if input.users[_].role == "admin"
+
+allow if not input.anonymous
+
+allow if user.roles[_] == "admin"
+
+allow if glob.match("/api/*", ["/"], input.path)
+
+allow if is_admin
+
+is_admin if user.admin == true
+
+f(x) if x == "a"
+
+f(x) if startswith(x, "b")
+
+deny contains "too big" if input.size > 10
+
+deny contains "too small" if input.size < 1
+`})
+
+	result := testutil.Must(rules.NewNonIndexableRule(config.Config{}).Run(context.Background(), input))(t)
+
+	violations := make([]string, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		violations = append(violations, violation.Location.String()+": "+violation.Description)
+	}
+
+	expected := []string{
+		"p.rego:9:10: Rule body can't be indexed: `input.size > 5` compares using >, " +
+			"while only equality (== or =) can be indexed",
+		"p.rego:13:2: Rule body can't be indexed: `u.name == \"bob\"` compares the local variable u, " +
+			"rather than input, data or a function argument",
+		"p.rego:16:10: Rule body can't be indexed: `input.users[_].role == \"admin\"` " +
+			"references input.users[_].role, which contains variables",
+		"p.rego:18:10: Rule body can't be indexed: `not input.anonymous` is negated",
+		"p.rego:20:10: Rule body can't be indexed: `user.roles[_] == \"admin\"` " +
+			"references user.roles[_], which contains variables",
+		"p.rego:24:1: Rule body can't be indexed: no expression compares input, data or a function argument " +
+			"to a value using ==",
+		"p.rego:30:9: Rule body can't be indexed: `startswith(x, \"b\")` calls startswith, " +
+			"while only equality (== or =) can be indexed",
+	}
+
+	if !slices.Equal(violations, expected) {
+		t.Errorf("expected violations:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(violations, "\n"))
+	}
+}
+
+func TestNonIndexableRuleCompileError(t *testing.T) {
+	t.Parallel()
+
+	input := inputPolicies(t, map[string]string{
+		"p.rego": "package p\n\nimport rego.v1\n\nallow if input.x == \"a\"\n\nallow if input.y > unknown\n",
+	})
+
+	result := testutil.Must(rules.NewNonIndexableRule(config.Config{}).Run(context.Background(), input))(t)
+
+	if len(result.Violations) != 0 {
+		t.Errorf("expected no violations, got %v", result.Violations)
+	}
+}
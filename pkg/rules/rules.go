@@ -41,6 +41,14 @@ type Rule interface {
 	Config() config.Rule
 }
 
+// ProgramRule is a Rule checking all input modules together, like when compiling them, so that violations
+// reported in one file may depend on the contents of other files. Violations of these rules are therefore
+// never cached per file, but found by running the rules over all files linted, cached or not.
+type ProgramRule interface {
+	Rule
+	programRule()
+}
+
 // NewInput creates a new Input from a set of modules.
 func NewInput(fileContent map[string]string, modules map[string]*ast.Module) Input {
 	// Maintain order across runs
@@ -111,5 +119,10 @@ func InputFromText(fileName, text string) (Input, error) {
 func AllGoRules(conf config.Config) []Rule {
 	return []Rule{
 		NewOpaFmtRule(conf),
+		NewCompileErrorRule(conf),
+		NewSchemaMismatchRule(conf),
+		NewUnsupportedCapabilityRule(conf),
+		NewNonIndexableRule(conf),
+		NewUnusedIgnoreDirectiveRule(conf),
 	}
 }
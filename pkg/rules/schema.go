@@ -0,0 +1,297 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/open-policy-agent/opa/util"
+
+	"github.com/styrainc/regal/internal/compile"
+	"github.com/styrainc/regal/internal/docs"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/report"
+)
+
+// SchemaMismatchRule reports references to input and data not matching the JSON schemas provided for them, either
+// by schemas annotations in METADATA, or by mapping schemas to packages and entrypoints in the configuration.
+// Errors reported are those found by the type checker only when taking the schemas into account, as any other
+// errors are reported by the compile-error rule.
+type SchemaMismatchRule struct {
+	ruleConfig   config.Rule
+	capabilities *ast.Capabilities
+	schemas      config.Schemas
+}
+
+const (
+	schemaMismatchTitle       = "schema-mismatch"
+	schemaMismatchDescription = "Reference or type does not match schema"
+	schemaMismatchCategory    = "bugs"
+)
+
+func NewSchemaMismatchRule(conf config.Config) *SchemaMismatchRule {
+	capabilities, _ := astCapabilities(conf.Capabilities)
+
+	rule := &SchemaMismatchRule{
+		ruleConfig:   config.Rule{Level: "error"},
+		capabilities: capabilities,
+	}
+
+	if ruleConf, ok := conf.Rules[schemaMismatchCategory][schemaMismatchTitle]; ok {
+		rule.ruleConfig = ruleConf
+	}
+
+	if conf.Schemas != nil {
+		rule.schemas = *conf.Schemas
+	}
+
+	return rule
+}
+
+func (s *SchemaMismatchRule) Run(ctx context.Context, input Input) (*report.Report, error) {
+	result := &report.Report{}
+
+	annotated := hasSchemaAnnotations(input)
+
+	if len(input.FileNames) == 0 || (!annotated && len(s.schemas.Input) == 0) {
+		return result, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout when running %s rule: %w", schemaMismatchTitle, ctx.Err())
+	default:
+	}
+
+	schemas, err := s.loadSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	// errors found without schemas are left for the compile-error rule to report
+	seen := make(map[string]struct{})
+
+	for _, err := range compileInput(input, ast.NewCompiler().WithCapabilities(s.capabilities)).Errors {
+		seen[errorKey(err)] = struct{}{}
+	}
+
+	addViolations := func(errs ast.Errors, inScope func(*ast.Error) bool) {
+		for _, err := range errs {
+			key := errorKey(err)
+			if _, ok := seen[key]; ok || isUndefinedDataFunction(err) || !inScope(err) {
+				continue
+			}
+
+			seen[key] = struct{}{}
+
+			violation := violationFromError(s, err, input)
+
+			// the message alone doesn't tell which types don't match
+			if detail, ok := err.Details.(*ast.UnificationErrDetail); ok {
+				violation.Description += fmt.Sprintf(" (%s and %s)", types.Sprint(detail.Left), types.Sprint(detail.Right))
+			}
+
+			result.Violations = append(result.Violations, violation)
+		}
+	}
+
+	if annotated {
+		compiler := compileInput(input, s.newCompiler(schemaSet(schemas, nil)))
+
+		addViolations(compiler.Errors, func(*ast.Error) bool { return true })
+	}
+
+	// input schemas mapped in configuration are applied globally to the input document, so each
+	// schema is checked separately, reporting only errors found in the packages or rules it's mapped to
+	mappings := make(map[string][]config.SchemaMapping)
+	ids := make([]string, 0)
+
+	for _, mapping := range s.schemas.Input {
+		id := strings.TrimPrefix(mapping.Schema, "schema.")
+		if _, ok := mappings[id]; !ok {
+			ids = append(ids, id)
+		}
+
+		mappings[id] = append(mappings[id], mapping)
+	}
+
+	for _, id := range ids {
+		schema, ok := schemas["schema."+id]
+		if !ok {
+			return nil, fmt.Errorf("schema %s mapped to input not found in schema directory %q", id, s.schemas.Directory)
+		}
+
+		compiler := compileInput(input, s.newCompiler(schemaSet(schemas, schema)))
+
+		addViolations(compiler.Errors, mappingsScope(input, mappings[id]))
+	}
+
+	return result, nil
+}
+
+func (s *SchemaMismatchRule) newCompiler(schemas *ast.SchemaSet) *ast.Compiler {
+	return ast.NewCompiler().
+		WithCapabilities(s.capabilities).
+		WithSchemas(schemas).
+		WithUseTypeCheckAnnotations(true)
+}
+
+// loadSchemas loads the schemas found in the schema directory, keyed by their ID, along with the schemas provided by
+// Regal for custom rules. Files are identified the same way as when providing a directory to the --schema flag of
+// OPA, i.e. by their path relative to the directory, without the extension.
+func (s *SchemaMismatchRule) loadSchemas() (map[string]any, error) {
+	regalSchemas := compile.RegalSchemaSet()
+
+	schemas := map[string]any{
+		"schema.regal.ast":       regalSchemas.Get(ast.MustParseRef("schema.regal.ast")),
+		"schema.regal.aggregate": regalSchemas.Get(ast.MustParseRef("schema.regal.aggregate")),
+	}
+
+	if s.schemas.Directory == "" {
+		return schemas, nil
+	}
+
+	err := filepath.WalkDir(s.schemas.Directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		var schema any
+		if err := util.Unmarshal(bs, &schema); err != nil {
+			return fmt.Errorf("failed to parse schema %s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(s.schemas.Directory, path)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		id := strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+
+		schemas["schema."+strings.ReplaceAll(id, "/", ".")] = schema
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schemas from %s: %w", s.schemas.Directory, err)
+	}
+
+	return schemas, nil
+}
+
+// schemaSet returns a schema set of schemas, where input, if not nil, is the schema used for the input document.
+func schemaSet(schemas map[string]any, input any) *ast.SchemaSet {
+	set := ast.NewSchemaSet()
+
+	for id, schema := range schemas {
+		set.Put(ast.MustParseRef(id), schema)
+	}
+
+	if input != nil {
+		set.Put(ast.SchemaRootRef, input)
+	}
+
+	return set
+}
+
+// mappingsScope returns a function determining if an error is found in any of the packages or entrypoints mapped.
+func mappingsScope(input Input, mappings []config.SchemaMapping) func(*ast.Error) bool {
+	return func(err *ast.Error) bool {
+		if err.Location == nil {
+			return false
+		}
+
+		module, ok := input.Modules[err.Location.File]
+		if !ok {
+			return false
+		}
+
+		pkg := strings.TrimPrefix(module.Package.Path.String(), "data.")
+
+		for _, mapping := range mappings {
+			if mapping.Package != "" && mapping.Package == pkg {
+				return true
+			}
+
+			if mapping.Entrypoint == "" || !strings.HasPrefix(mapping.Entrypoint, pkg+".") {
+				continue
+			}
+
+			for _, rule := range module.Rules {
+				if rule.Path().String() == "data."+mapping.Entrypoint && withinRule(rule, err.Location) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+func withinRule(rule *ast.Rule, loc *ast.Location) bool {
+	end := rule.Location.Row + strings.Count(string(rule.Location.Text), "\n")
+
+	return loc.Row >= rule.Location.Row && loc.Row <= end
+}
+
+func hasSchemaAnnotations(input Input) bool {
+	for _, filename := range input.FileNames {
+		for _, annotation := range input.Modules[filename].Annotations {
+			if len(annotation.Schemas) > 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func errorKey(err *ast.Error) string {
+	if err.Location == nil {
+		return err.Code + ":" + err.Message
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%d:%d", err.Code, err.Message, err.Location.File, err.Location.Row, err.Location.Col)
+}
+
+func (*SchemaMismatchRule) Name() string {
+	return schemaMismatchTitle
+}
+
+func (*SchemaMismatchRule) Category() string {
+	return schemaMismatchCategory
+}
+
+func (*SchemaMismatchRule) Description() string {
+	return schemaMismatchDescription
+}
+
+func (*SchemaMismatchRule) Documentation() string {
+	return docs.CreateDocsURL(schemaMismatchCategory, schemaMismatchTitle)
+}
+
+func (s *SchemaMismatchRule) Config() config.Rule {
+	return s.ruleConfig
+}
+
+func (*SchemaMismatchRule) programRule() {}
@@ -0,0 +1,142 @@
+package rules_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/styrainc/regal/internal/testutil"
+	"github.com/styrainc/regal/pkg/config"
+	"github.com/styrainc/regal/pkg/rules"
+)
+
+const admissionSchema = `{
+  "type": "object",
+  "properties": {
+    "kind": {"type": "string"},
+    "replicas": {"type": "integer"}
+  },
+  "additionalProperties": false
+}`
+
+func TestSchemaMismatchRule(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		policies map[string]string
+		mappings []config.SchemaMapping
+		expected []string
+	}{
+		"package mapping": {
+			policies: map[string]string{
+				"p.rego": "package k8s\n\nimport rego.v1\n\ndeny if input.kinds == \"Pod\"\n\ndeny if input.replicas == \"3\"\n",
+				"q.rego": "package other\n\nimport rego.v1\n\nallow if input.kinds\n",
+			},
+			mappings: []config.SchemaMapping{{Package: "k8s", Schema: "k8s.admission"}},
+			expected: []string{
+				"p.rego:5:9: rego_type_error: undefined ref: input.kinds",
+				"p.rego:7:9: rego_type_error: match error (number and string)",
+			},
+		},
+		"entrypoint mapping": {
+			policies: map[string]string{
+				"p.rego": "package k8s\n\nimport rego.v1\n\ndeny if input.kinds == \"Pod\"\n\nallow if input.kinds\n",
+			},
+			mappings: []config.SchemaMapping{{Entrypoint: "k8s.allow", Schema: "schema.k8s.admission"}},
+			expected: []string{"p.rego:7:10: rego_type_error: undefined ref: input.kinds"},
+		},
+		"annotation": {
+			policies: map[string]string{
+				"p.rego": `package k8s
+
+import rego.v1
+
+# METADATA
+# schemas:
+#   - input: schema.k8s.admission
+deny if input.kinds == "Pod"
+
+allow if input.kinds
+`,
+			},
+			expected: []string{"p.rego:8:9: rego_type_error: undefined ref: input.kinds"},
+		},
+		"unknown schema in annotation": {
+			policies: map[string]string{
+				"p.rego": `package k8s
+
+import rego.v1
+
+# METADATA
+# schemas:
+#   - input: schema.k8s.admision
+deny if input.kind == "Pod"
+`,
+			},
+			expected: []string{"p.rego:8:1: rego_type_error: undefined schema: schema.k8s.admision"},
+		},
+		"no schemas": {
+			policies: map[string]string{
+				"p.rego": "package k8s\n\nimport rego.v1\n\ndeny if input.kinds == \"Pod\"\n",
+			},
+		},
+	}
+
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "k8s"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "k8s", "admission.json"), []byte(admissionSchema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.Config{Schemas: &config.Schemas{Directory: dir, Input: tc.mappings}}
+
+			input := inputPolicies(t, tc.policies)
+			result := testutil.Must(rules.NewSchemaMismatchRule(conf).Run(context.Background(), input))(t)
+
+			violations := make([]string, 0, len(result.Violations))
+			for _, violation := range result.Violations {
+				violations = append(violations, violation.Location.String()+": "+violation.Description)
+			}
+
+			slices.Sort(violations)
+
+			if !slices.Equal(violations, tc.expected) {
+				t.Errorf("expected violations:\n%s\ngot:\n%s",
+					strings.Join(tc.expected, "\n"), strings.Join(violations, "\n"))
+			}
+
+			for _, violation := range result.Violations {
+				if violation.Title != "schema-mismatch" || violation.Level != "error" {
+					t.Errorf("unexpected violation %s/%s with level %s", violation.Category, violation.Title, violation.Level)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaMismatchRuleMappedSchemaNotFound(t *testing.T) {
+	t.Parallel()
+
+	conf := config.Config{Schemas: &config.Schemas{
+		Directory: t.TempDir(),
+		Input:     []config.SchemaMapping{{Package: "k8s", Schema: "k8s.admission"}},
+	}}
+
+	input := inputPolicies(t, map[string]string{"p.rego": "package k8s\n\nimport rego.v1\n\ndeny if input.kind\n"})
+
+	_, err := rules.NewSchemaMismatchRule(conf).Run(context.Background(), input)
+	if err == nil || !strings.Contains(err.Error(), "schema k8s.admission mapped to input not found") {
+		t.Errorf("expected error for schema not found, got %v", err)
+	}
+}